@@ -0,0 +1,87 @@
+package transform
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// boolValues maps the recognized (lowercased) spellings of boolean values to
+// their truth value.
+var boolValues = map[string]bool{
+	"1":        true,
+	"t":        true,
+	"true":     true,
+	"y":        true,
+	"yes":      true,
+	"on":       true,
+	"enable":   true,
+	"enabled":  true,
+	"0":        false,
+	"f":        false,
+	"false":    false,
+	"n":        false,
+	"no":       false,
+	"off":      false,
+	"disable":  false,
+	"disabled": false,
+}
+
+// parseBool returns the truth value of a boolean-like string, ignoring case
+// and surrounding whitespace.
+func parseBool(s string) (bool, error) {
+	b, ok := boolValues[strings.ToLower(strings.TrimSpace(s))]
+	if !ok {
+		return false, errors.New("invalid boolean value: " + s)
+	}
+	return b, nil
+}
+
+// TriState returns a function that maps boolean-like strings to the given
+// true or false token, and empty strings to the given empty token.
+// Unrecognized values result in an error.
+func (*Transform) TriState(yes, no, empty string) TransformFunc {
+	return func(s string) (string, error) {
+		if strings.TrimSpace(s) == "" {
+			return empty, nil
+		}
+		b, err := parseBool(s)
+		if err != nil {
+			return "", err
+		}
+		if b {
+			return yes, nil
+		}
+		return no, nil
+	}
+}
+
+// NotBool parses a boolean-like string and returns the negated value as
+// "true" or "false".
+func (*Transform) NotBool(s string) (string, error) {
+	b, err := parseBool(s)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatBool(!b), nil
+}
+
+// BoolSymbol returns a function that maps boolean-like strings to the given
+// true or false symbol. Unrecognized values are mapped to the unknown symbol
+// if it is non-empty, and result in an error otherwise.
+func (*Transform) BoolSymbol(yes, no, unknown string) TransformFunc {
+	return func(s string) (string, error) {
+		b, err := parseBool(s)
+		if err != nil {
+			if unknown != "" {
+				return unknown, nil
+			}
+			return "", err
+		}
+		if b {
+			return yes, nil
+		}
+		return no, nil
+	}
+}
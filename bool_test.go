@@ -0,0 +1,76 @@
+package transform
+
+import (
+	"testing"
+)
+
+func TestTriState(t *testing.T) {
+	tests := []struct {
+		rule, in, want string
+		ok             bool
+	}{
+		{"tristate", " Yes ", "true", true},
+		{"tristate", "OFF", "false", true},
+		{"tristate", "", "", true},
+		{"tristate:1:0:-", "enabled", "1", true},
+		{"tristate:1:0:-", "n", "0", true},
+		{"tristate:1:0:-", "  ", "-", true},
+		{"tristate", "maybe", "", false},
+	}
+	y := New()
+	for _, tt := range tests {
+		got, err := applyRule(y, tt.rule, tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := y.ParseStringRule("tristate:a:b:c:d"); err == nil {
+		t.Error("expected error for too many arguments")
+	}
+}
+
+func TestNotBool(t *testing.T) {
+	tests := []struct {
+		in, want string
+		ok       bool
+	}{
+		{"yes", "false", true},
+		{" OFF ", "true", true},
+		{"0", "true", true},
+		{"", "", false},
+		{"maybe", "", false},
+	}
+	y := New()
+	for _, tt := range tests {
+		got, err := applyRule(y, "notbool", tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("notbool(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestBoolSymbol(t *testing.T) {
+	tests := []struct {
+		rule, in, want string
+		ok             bool
+	}{
+		{"boolsymbol", "yes", "✓", true},
+		{"boolsymbol", "off", "✗", true},
+		{"boolsymbol", "maybe", "", false},
+		{"boolsymbol:Y:N", "1", "Y", true},
+		{"boolsymbol:Y:N:?", "maybe", "?", true},
+		{"boolsymbol:Y:N:?", "false", "N", true},
+	}
+	y := New()
+	for _, tt := range tests {
+		got, err := applyRule(y, tt.rule, tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+	for _, rule := range []string{"boolsymbol:Y", "boolsymbol:a:b:c:d"} {
+		if _, err := y.ParseStringRule(rule); err == nil {
+			t.Errorf("%s: expected error", rule)
+		}
+	}
+}
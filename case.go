@@ -0,0 +1,137 @@
+package transform
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// caseConventions lists the naming conventions supported by Recase.
+var caseConventions = map[string]bool{
+	"snake":    true,
+	"constant": true,
+	"kebab":    true,
+	"camel":    true,
+	"pascal":   true,
+	"title":    true,
+}
+
+// splitCase splits a string into words according to the given naming
+// convention. For camel and pascal case, words start at an uppercase letter
+// following a lowercase letter or digit, and at the last uppercase letter of
+// a run followed by a lowercase letter, so "HTTPServer2Go" becomes "HTTP",
+// "Server2" and "Go".
+func splitCase(s, conv string) []string {
+	var words []string
+	switch conv {
+	case "snake", "constant":
+		words = strings.Split(s, "_")
+	case "kebab":
+		words = strings.Split(s, "-")
+	case "title":
+		words = strings.Fields(s)
+	case "camel", "pascal":
+		rr := []rune(s)
+		start := 0
+		for i := 1; i < len(rr); i++ {
+			if !unicode.IsUpper(rr[i]) {
+				continue
+			}
+			prev := rr[i-1]
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) ||
+				unicode.IsUpper(prev) && i+1 < len(rr) && unicode.IsLower(rr[i+1]) {
+				words = append(words, string(rr[start:i]))
+				start = i
+			}
+		}
+		words = append(words, string(rr[start:]))
+	}
+
+	n := 0
+	for _, w := range words {
+		if w = strings.TrimSpace(w); w != "" {
+			words[n] = w
+			n++
+		}
+	}
+	return words[:n]
+}
+
+// capitalizeWord returns the word with its first rune uppercased and the
+// rest lowercased.
+func capitalizeWord(w string) string {
+	r, n := utf8.DecodeRuneInString(w)
+	if r == utf8.RuneError && n <= 1 {
+		// Empty string or invalid UTF-8, keep the first byte as is.
+		return w[:n] + strings.ToLower(w[n:])
+	}
+	return string(unicode.ToUpper(r)) + strings.ToLower(w[n:])
+}
+
+// joinCase joins words according to the given naming convention.
+func joinCase(words []string, conv string) string {
+	out := make([]string, len(words))
+	for i, w := range words {
+		switch conv {
+		case "snake", "kebab":
+			out[i] = strings.ToLower(w)
+		case "constant":
+			out[i] = strings.ToUpper(w)
+		case "camel":
+			if i == 0 {
+				out[i] = strings.ToLower(w)
+			} else {
+				out[i] = capitalizeWord(w)
+			}
+		case "pascal", "title":
+			out[i] = capitalizeWord(w)
+		}
+	}
+
+	switch conv {
+	case "snake", "constant":
+		return strings.Join(out, "_")
+	case "kebab":
+		return strings.Join(out, "-")
+	case "title":
+		return strings.Join(out, " ")
+	}
+	return strings.Join(out, "")
+}
+
+// Recase returns a function that converts an identifier from one naming
+// convention to another. Supported conventions are snake (user_name),
+// constant (USER_NAME), kebab (user-name), camel (userName), pascal
+// (UserName) and title (User Name).
+func (*Transform) Recase(from, to string) (TransformFunc, error) {
+	if !caseConventions[from] {
+		return nil, errors.New("unknown naming convention: " + from)
+	}
+	if !caseConventions[to] {
+		return nil, errors.New("unknown naming convention: " + to)
+	}
+	return func(s string) (string, error) {
+		return joinCase(splitCase(s, from), to), nil
+	}, nil
+}
+
+// splitAnyCase splits a string in any of the supported naming conventions
+// into words, i.e. at underscores, hyphens, whitespace and camel case
+// boundaries (see splitCase).
+func splitAnyCase(s string) []string {
+	var words []string
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool {
+		return r == '_' || r == '-' || unicode.IsSpace(r)
+	}) {
+		words = append(words, splitCase(part, "camel")...)
+	}
+	return words
+}
+
+// Constant converts an identifier in any of the supported naming
+// conventions (see Recase) to constant case, e.g. "USER_NAME".
+func (*Transform) Constant(s string) (string, error) {
+	return joinCase(splitAnyCase(s), "constant"), nil
+}
@@ -0,0 +1,50 @@
+package transform
+
+import (
+	"testing"
+)
+
+func TestRecase(t *testing.T) {
+	tests := []struct {
+		rule, in, want string
+	}{
+		{"recase:snake:camel", "user_name_id", "userNameId"},
+		{"recase:camel:snake", "HTTPServer2Go", "http_server2_go"},
+		{"recase:pascal:kebab", "UserName", "user-name"},
+		{"recase:kebab:constant", "user--name", "USER_NAME"},
+		{"recase:title:pascal", "  user  name ", "UserName"},
+		{"recase:CONSTANT:Title", "ÉCOLE_NAME", "École Name"},
+		{"recase:snake:camel", "", ""},
+	}
+	y := New()
+	for _, tt := range tests {
+		if got, err := applyRule(y, tt.rule, tt.in); err != nil || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+	for _, rule := range []string{"recase", "recase:snake", "recase:snake:dot", "recase:dot:snake"} {
+		if _, err := y.ParseStringRule(rule); err == nil {
+			t.Errorf("%s: expected error", rule)
+		}
+	}
+}
+
+func TestConstant(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"userName", "USER_NAME"},
+		{"user-name id", "USER_NAME_ID"},
+		{"HTTPServer", "HTTP_SERVER"},
+		{"already_CONSTANT", "ALREADY_CONSTANT"},
+		{"", ""},
+	}
+	y := New()
+	for _, tt := range tests {
+		for _, rule := range []string{"constant", "screaming_snake"} {
+			if got, err := applyRule(y, rule, tt.in); err != nil || got != tt.want {
+				t.Errorf("%s(%q) = %q, %v, want %q", rule, tt.in, got, err, tt.want)
+			}
+		}
+	}
+}
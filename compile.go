@@ -0,0 +1,81 @@
+package transform
+
+// CompiledTransform is an immutable snapshot of a transformation
+// configuration that is safe for concurrent use, provided that the functions
+// and readers supplied by the caller, e.g. lookup functions, are safe for
+// concurrent use as well. It is created by Transform.Compile.
+type CompiledTransform struct {
+	t     Transform
+	rules []TransformFunc
+}
+
+// Compile returns a snapshot of the current configuration, i.e. its rules,
+// lookups, handlers and options. Later changes to the Transform do not affect
+// the snapshot. Default handlers are bound to the snapshot, and rules added as
+// strings, e.g. by AddStringRules, are parsed again against it, so that they
+// use its lookups and options. Default handlers are identified by their tag,
+// so handlers replacing a default must be registered with the Handler or
+// ArgHandler option rather than by writing to the maps directly. Rules given
+// as functions, e.g. by the Rule option, are used as is. If Transform.Rules
+// was modified directly, all rules are treated as functions. Nil rules are
+// dropped from the chain.
+func (t *Transform) Compile() *CompiledTransform {
+	c := &CompiledTransform{t: *t}
+	s := &c.t
+
+	s.Lookups = append([]LookupFunc(nil), t.Lookups...)
+	s.RuleStrings = append([]string(nil), t.RuleStrings...)
+	s.NamedLookups = make(map[string]LookupFunc, len(t.NamedLookups))
+	for name, f := range t.NamedLookups {
+		s.NamedLookups[name] = f
+	}
+
+	defaults := s.defaultHandlers()
+	s.Handlers = make(Handlers, len(t.Handlers))
+	for tag, f := range t.Handlers {
+		if d := defaults[tag]; d != nil && t.defaultTags[tag] {
+			f = d
+		}
+		s.Handlers[tag] = f
+	}
+	argDefaults := s.defaultArgHandlers()
+	s.ArgHandlers = make(ArgHandlers, len(t.ArgHandlers))
+	for tag, f := range t.ArgHandlers {
+		if d := argDefaults[tag]; d != nil && t.defaultArgTags[tag] {
+			f = d
+		}
+		s.ArgHandlers[tag] = f
+	}
+
+	src := t.ruleSrc
+	if len(src) != len(t.Rules) {
+		src = nil
+	}
+	c.rules = make([]TransformFunc, 0, len(t.Rules))
+	for i, f := range t.Rules {
+		if f == nil {
+			continue
+		}
+		if src != nil && src[i] != "" {
+			// The rule parsed before, so an error means that its handler
+			// was removed since. Keep the original function in that case.
+			if g, err := s.ParseStringRule(src[i]); err == nil {
+				f = g
+			}
+		}
+		c.rules = append(c.rules, f)
+	}
+	s.Rules = c.rules
+	s.ruleSrc = nil
+	return c
+}
+
+// Transform applies the compiled rules to the given string.
+func (c *CompiledTransform) Transform(s string) (string, error) {
+	return c.t.apply(s, c.rules)
+}
+
+// Func returns the compiled rule chain as a single transformation function.
+func (c *CompiledTransform) Func() TransformFunc {
+	return c.Transform
+}
@@ -0,0 +1,86 @@
+package transform
+
+import (
+	"testing"
+)
+
+func TestCompile(t *testing.T) {
+	y := New()
+	if err := y.AddStringRules("trim,upcase"); err != nil {
+		t.Fatal(err)
+	}
+	f := y.Compile().Func()
+	y.Rules = append(y.Rules, y.Downcase)
+	if s, err := f(" a "); err != nil || s != "A" {
+		t.Errorf("got %q, %v, want %q", s, err, "A")
+	}
+	if s, err := y.Transform(" a "); err != nil || s != "a" {
+		t.Errorf("original: got %q, %v, want %q", s, err, "a")
+	}
+}
+
+func benchmarkTransform() *Transform {
+	y := New(Lookup(LookupHandlers(map[string]string{"name": "World"})))
+	if err := y.AddStringRules(`trim,expand:\$\{(?P<key>\w+)\},upcase`); err != nil {
+		panic(err)
+	}
+	return y
+}
+
+func BenchmarkTransform(b *testing.B) {
+	y := benchmarkTransform()
+	for i := 0; i < b.N; i++ {
+		if _, err := y.Transform("  Hello ${name}!  "); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCompiled(b *testing.B) {
+	f := benchmarkTransform().Compile().Func()
+	for i := 0; i < b.N; i++ {
+		if _, err := f("  Hello ${name}!  "); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestCompileSnapshot(t *testing.T) {
+	y := New(Lookup(LookupHandlers(map[string]string{"x": "A"})))
+	if err := y.AddStringRules(`expand:\$\{(?P<key>\w+)\}`, "downcase"); err != nil {
+		t.Fatal(err)
+	}
+	c := y.Compile()
+
+	y.Lookups[0] = LookupHandlers(map[string]string{"x": "B"})
+	y.Rules[1] = y.Upcase
+
+	if s, err := c.Transform("${x}"); err != nil || s != "a" {
+		t.Errorf("compiled: got %q, %v, want %q", s, err, "a")
+	}
+	if s, err := y.Transform("${x}"); err != nil || s != "B" {
+		t.Errorf("original: got %q, %v, want %q", s, err, "B")
+	}
+}
+
+func TestCompileFuncRules(t *testing.T) {
+	y := New(Rule(nil, func(s string) (string, error) { return s + "!", nil }))
+	if err := y.AddStringRules("upcase"); err != nil {
+		t.Fatal(err)
+	}
+	c := y.Compile()
+	y.Rules = nil
+	if s, err := c.Transform("a"); err != nil || s != "A!" {
+		t.Errorf("got %q, %v, want %q", s, err, "A!")
+	}
+}
+
+func TestCompileHandlers(t *testing.T) {
+	y := New(Handler("trim", func(s string) (string, error) { return "<" + s + ">", nil }))
+	if err := y.AddStringRules("trim", "downcase"); err != nil {
+		t.Fatal(err)
+	}
+	if s, err := y.Compile().Transform(" A "); err != nil || s != "< a >" {
+		t.Errorf("got %q, %v, want %q", s, err, "< a >")
+	}
+}
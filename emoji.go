@@ -0,0 +1,177 @@
+package transform
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// emojiTable contains the code point ranges treated as emoji: regional
+// indicators (U+1F1E6–U+1F1FF), miscellaneous symbols and pictographs
+// (U+1F300–U+1F5FF), emoticons (U+1F600–U+1F64F), transport and map symbols
+// (U+1F680–U+1F6FF), supplemental symbols and pictographs (U+1F900–U+1F9FF),
+// symbols and pictographs extended-A (U+1FA70–U+1FAFF), and the characters
+// of miscellaneous symbols (U+2600–U+26FF) and dingbats (U+2700–U+27BF) that
+// have the Emoji_Presentation property. Other characters of the latter two
+// blocks, e.g. "★" or "✓", are only treated as emoji if followed by the
+// emoji variation selector U+FE0F (see isEmoji).
+var emojiTable = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x2614, Hi: 0x2615, Stride: 1},
+		{Lo: 0x2648, Hi: 0x2653, Stride: 1},
+		{Lo: 0x267f, Hi: 0x2693, Stride: 0x14},
+		{Lo: 0x26a1, Hi: 0x26a1, Stride: 1},
+		{Lo: 0x26aa, Hi: 0x26ab, Stride: 1},
+		{Lo: 0x26bd, Hi: 0x26be, Stride: 1},
+		{Lo: 0x26c4, Hi: 0x26c5, Stride: 1},
+		{Lo: 0x26ce, Hi: 0x26d4, Stride: 6},
+		{Lo: 0x26ea, Hi: 0x26ea, Stride: 1},
+		{Lo: 0x26f2, Hi: 0x26f3, Stride: 1},
+		{Lo: 0x26f5, Hi: 0x26fa, Stride: 5},
+		{Lo: 0x26fd, Hi: 0x26fd, Stride: 1},
+		{Lo: 0x2705, Hi: 0x2705, Stride: 1},
+		{Lo: 0x270a, Hi: 0x270b, Stride: 1},
+		{Lo: 0x2728, Hi: 0x2728, Stride: 1},
+		{Lo: 0x274c, Hi: 0x274e, Stride: 2},
+		{Lo: 0x2753, Hi: 0x2755, Stride: 1},
+		{Lo: 0x2757, Hi: 0x2757, Stride: 1},
+		{Lo: 0x2795, Hi: 0x2797, Stride: 1},
+		{Lo: 0x27b0, Hi: 0x27bf, Stride: 0xf},
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x1f1e6, Hi: 0x1f1ff, Stride: 1},
+		{Lo: 0x1f300, Hi: 0x1f64f, Stride: 1},
+		{Lo: 0x1f680, Hi: 0x1f6ff, Stride: 1},
+		{Lo: 0x1f900, Hi: 0x1f9ff, Stride: 1},
+		{Lo: 0x1fa70, Hi: 0x1faff, Stride: 1},
+	},
+}
+
+// isEmoji reports whether r, followed by next, starts an emoji: r is in
+// emojiTable, or r is a symbol in U+2600–U+27BF followed by the emoji
+// variation selector U+FE0F.
+func isEmoji(r, next rune) bool {
+	return unicode.Is(emojiTable, r) || 0x2600 <= r && r <= 0x27bf && next == 0xfe0f
+}
+
+// isEmojiModifier reports whether r may be part of an emoji sequence
+// following an emoji: variation selectors (U+FE0E, U+FE0F), the combining
+// enclosing keycap (U+20E3), the zero width joiner (U+200D) and tag
+// characters (U+E0020–U+E007F).
+func isEmojiModifier(r rune) bool {
+	return r == 0xfe0e || r == 0xfe0f || r == 0x20e3 || r == 0x200d || 0xe0020 <= r && r <= 0xe007f
+}
+
+// replaceEmoji replaces each emoji sequence with the result of the given
+// function called with its first rune. A sequence is an emoji (see
+// emojiTable) followed by modifiers (see isEmojiModifier), skin tone
+// modifiers (U+1F3FB–U+1F3FF) or further emoji joined by zero width
+// joiners. A pair of regional indicators forms a single flag sequence.
+func replaceEmoji(s string, f func(rune) string) string {
+	var b strings.Builder
+	rr := []rune(s)
+	var prev rune
+	inSeq, flag := false, false
+	for i, r := range rr {
+		var next rune
+		if i+1 < len(rr) {
+			next = rr[i+1]
+		}
+		emoji := isEmoji(r, next)
+		isRI := 0x1f1e6 <= r && r <= 0x1f1ff
+		switch {
+		case inSeq && (isEmojiModifier(r) || 0x1f3fb <= r && r <= 0x1f3ff ||
+			prev == 0x200d && (emoji || 0x2600 <= r && r <= 0x27bf) || isRI && flag):
+			flag = false
+		case emoji:
+			b.WriteString(f(r))
+			inSeq, flag = true, isRI
+		default:
+			b.WriteRune(r)
+			inSeq, flag = false, false
+		}
+		prev = r
+	}
+	return b.String()
+}
+
+// StripEmoji removes emoji sequences (see replaceEmoji) from the string.
+func (*Transform) StripEmoji(s string) (string, error) {
+	return replaceEmoji(s, func(rune) string { return "" }), nil
+}
+
+// emojiNames indexes the CLDR short names of common emoji, with spaces
+// replaced by underscores, by their first code point.
+var emojiNames = map[rune]string{
+	0x2615:  "hot_beverage",
+	0x2614:  "umbrella_with_rain_drops",
+	0x26a1:  "high_voltage",
+	0x26bd:  "soccer_ball",
+	0x2705:  "check_mark_button",
+	0x2728:  "sparkles",
+	0x274c:  "cross_mark",
+	0x2764:  "red_heart",
+	0x1f308: "rainbow",
+	0x1f31f: "glowing_star",
+	0x1f381: "wrapped_gift",
+	0x1f389: "party_popper",
+	0x1f3b5: "musical_note",
+	0x1f440: "eyes",
+	0x1f44b: "waving_hand",
+	0x1f44c: "OK_hand",
+	0x1f44d: "thumbs_up",
+	0x1f44e: "thumbs_down",
+	0x1f44f: "clapping_hands",
+	0x1f494: "broken_heart",
+	0x1f499: "blue_heart",
+	0x1f49a: "green_heart",
+	0x1f49b: "yellow_heart",
+	0x1f49c: "purple_heart",
+	0x1f4aa: "flexed_biceps",
+	0x1f4af: "hundred_points",
+	0x1f525: "fire",
+	0x1f600: "grinning_face",
+	0x1f601: "beaming_face_with_smiling_eyes",
+	0x1f602: "face_with_tears_of_joy",
+	0x1f603: "grinning_face_with_big_eyes",
+	0x1f604: "grinning_face_with_smiling_eyes",
+	0x1f605: "grinning_face_with_sweat",
+	0x1f606: "grinning_squinting_face",
+	0x1f609: "winking_face",
+	0x1f60a: "smiling_face_with_smiling_eyes",
+	0x1f60d: "smiling_face_with_heart-eyes",
+	0x1f60e: "smiling_face_with_sunglasses",
+	0x1f60f: "smirking_face",
+	0x1f610: "neutral_face",
+	0x1f612: "unamused_face",
+	0x1f614: "pensive_face",
+	0x1f618: "face_blowing_a_kiss",
+	0x1f621: "enraged_face",
+	0x1f622: "crying_face",
+	0x1f62d: "loudly_crying_face",
+	0x1f631: "face_screaming_in_fear",
+	0x1f642: "slightly_smiling_face",
+	0x1f643: "upside-down_face",
+	0x1f644: "face_with_rolling_eyes",
+	0x1f64f: "folded_hands",
+	0x1f680: "rocket",
+	0x1f914: "thinking_face",
+	0x1f923: "rolling_on_the_floor_laughing",
+	0x1f970: "smiling_face_with_hearts",
+	0x1f973: "partying_face",
+	0x1f97a: "pleading_face",
+}
+
+// EmojiPlaceholder replaces each emoji sequence (see replaceEmoji) with a
+// ":name:" token in the style of demojize, e.g. ":thumbs_up:" for "👍🏽". The
+// name is the CLDR short name of its first rune; emoji that are not in the
+// small built-in table (see emojiNames) are named by code point instead,
+// e.g. ":U+1F9A9:".
+func (*Transform) EmojiPlaceholder(s string) (string, error) {
+	return replaceEmoji(s, func(r rune) string {
+		if name, ok := emojiNames[r]; ok {
+			return ":" + name + ":"
+		}
+		return fmt.Sprintf(":U+%04X:", r)
+	}), nil
+}
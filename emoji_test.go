@@ -0,0 +1,27 @@
+package transform
+
+import (
+	"testing"
+)
+
+func TestStripEmoji(t *testing.T) {
+	tests := []struct {
+		rule, in, want string
+	}{
+		{"stripemoji", "hi 😀!", "hi !"},
+		{"stripemoji", "ok ✓ ★ ☺", "ok ✓ ★ ☺"},
+		{"stripemoji", "done ✅ ⚡", "done  "},
+		{"stripemoji", "love ❤️ you", "love  you"},
+		{"stripemoji", "👍🏽 👨‍👩‍👧 🇩🇪", "  "},
+		{"stripemoji:placeholder", "great 👍🏽!", "great :thumbs_up:!"},
+		{"stripemoji:placeholder", "😂❤️", ":face_with_tears_of_joy::red_heart:"},
+		{"stripemoji:placeholder", "🦩", ":U+1F9A9:"},
+		{"stripemoji:placeholder", "✓ ★", "✓ ★"},
+	}
+	y := New()
+	for _, tt := range tests {
+		if got, err := y.Apply(tt.rule, tt.in); err != nil || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+}
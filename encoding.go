@@ -0,0 +1,90 @@
+package transform
+
+import (
+	"encoding/base64"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// Base64Text decodes a base64 string (standard alphabet, with or without
+// padding) and ensures that the result is valid UTF-8 text.
+func (*Transform) Base64Text(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	enc := base64.StdEncoding
+	if !strings.HasSuffix(s, "=") {
+		enc = base64.RawStdEncoding
+	}
+	b, err := enc.DecodeString(s)
+	if err != nil {
+		return "", errors.Wrap(err, "base64text")
+	}
+	if !utf8.Valid(b) {
+		return "", errors.New("base64text: decoded data is not valid UTF-8")
+	}
+	return string(b), nil
+}
+
+// charsets indexes the supported character encodings by (lowercase) name:
+// latin1 (iso-8859-1), latin9 (iso-8859-15), windows-1250 (cp1250),
+// windows-1251 (cp1251), windows-1252 (cp1252), koi8-r, cp437, utf-16le and
+// utf-16be.
+var charsets = map[string]encoding.Encoding{
+	"latin1":       charmap.ISO8859_1,
+	"iso-8859-1":   charmap.ISO8859_1,
+	"latin9":       charmap.ISO8859_15,
+	"iso-8859-15":  charmap.ISO8859_15,
+	"windows-1250": charmap.Windows1250,
+	"cp1250":       charmap.Windows1250,
+	"windows-1251": charmap.Windows1251,
+	"cp1251":       charmap.Windows1251,
+	"windows-1252": charmap.Windows1252,
+	"cp1252":       charmap.Windows1252,
+	"koi8-r":       charmap.KOI8R,
+	"cp437":        charmap.CodePage437,
+	"utf-16le":     unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM),
+	"utf-16be":     unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM),
+}
+
+// charset returns the character encoding with the given name.
+func charset(name string) (encoding.Encoding, error) {
+	enc, ok := charsets[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return nil, errors.New("unknown charset: " + name)
+	}
+	return enc, nil
+}
+
+// ToUTF8 returns a function that treats the bytes of its input as text in the
+// given character encoding and converts it to UTF-8.
+func (*Transform) ToUTF8(enc encoding.Encoding) TransformFunc {
+	return func(s string) (string, error) {
+		u, err := enc.NewDecoder().String(s)
+		if err != nil {
+			return "", errors.Wrap(err, "decode")
+		}
+		return u, nil
+	}
+}
+
+// FromUTF8 returns a function that converts its UTF-8 input to the given
+// character encoding. The result contains the raw bytes in that encoding.
+func (*Transform) FromUTF8(enc encoding.Encoding) TransformFunc {
+	return func(s string) (string, error) {
+		e, err := enc.NewEncoder().String(s)
+		if err != nil {
+			return "", errors.Wrap(err, "encode")
+		}
+		return e, nil
+	}
+}
+
+// StripBOM removes a single leading UTF-8 byte order mark (U+FEFF), if
+// present. Byte order marks elsewhere in the string are kept.
+func (*Transform) StripBOM(s string) (string, error) {
+	return strings.TrimPrefix(s, "\ufeff"), nil
+}
@@ -0,0 +1,93 @@
+package transform
+
+import (
+	"testing"
+)
+
+func TestBase64Text(t *testing.T) {
+	tests := []struct {
+		in, want string
+		ok       bool
+	}{
+		{"aGVsbG8=", "hello", true},
+		{" aGVsbG8 ", "hello", true},
+		{"w6k=", "é", true},
+		{"", "", true},
+		{"/w==", "", false},
+		{"aGVsbG8*", "", false},
+	}
+	y := New()
+	for _, tt := range tests {
+		got, err := applyRule(y, "base64text", tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("base64text(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestToUTF8(t *testing.T) {
+	tests := []struct {
+		rule, in, want string
+	}{
+		{"toutf8:latin1", "caf\xe9", "café"},
+		{"toutf8:ISO-8859-1", "\xfc", "ü"},
+		{"toutf8:latin9", "\xa4", "€"},
+		{"toutf8:cp1252", "\x80", "€"},
+		{"toutf8:koi8-r", "\xf0\xd2\xc9", "При"},
+	}
+	y := New()
+	for _, tt := range tests {
+		if got, err := applyRule(y, tt.rule, tt.in); err != nil || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+	for _, rule := range []string{"toutf8", "toutf8:ebcdic"} {
+		if _, err := y.ParseStringRule(rule); err == nil {
+			t.Errorf("%s: expected error", rule)
+		}
+	}
+}
+
+func TestDecodeEncode(t *testing.T) {
+	tests := []struct {
+		rule, in, want string
+	}{
+		{"decode:latin1", "caf\xe9", "café"},
+		{"encode:latin1", "café", "caf\xe9"},
+		{"encode:cp1252", "€", "\x80"},
+		{"encode:utf-16le", "hé", "h\x00\xe9\x00"},
+		{"decode:utf-16be", "\x00h\x00\xe9", "hé"},
+	}
+	y := New()
+	for _, tt := range tests {
+		if got, err := applyRule(y, tt.rule, tt.in); err != nil || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := applyRule(y, "encode:latin1", "€"); err == nil {
+		t.Error("expected error for unencodable character")
+	}
+	for _, rule := range []string{"decode", "encode:ebcdic"} {
+		if _, err := y.ParseStringRule(rule); err == nil {
+			t.Errorf("%s: expected error", rule)
+		}
+	}
+}
+
+func TestStripBOM(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"\ufeffhello", "hello"},
+		{"\ufeff\ufeffhello", "\ufeffhello"},
+		{"he\ufeffllo", "he\ufeffllo"},
+		{"hello", "hello"},
+		{"", ""},
+	}
+	y := New()
+	for _, tt := range tests {
+		if got, err := applyRule(y, "stripbom", tt.in); err != nil || got != tt.want {
+			t.Errorf("stripbom(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package transform
+
+import (
+	"strings"
+)
+
+// RuleFlag implements flag.Value for adding string rules to a Transform from
+// the command line. Each occurrence of the flag adds its rules after those of
+// previous occurrences, so that e.g. "--transform trim --transform downcase"
+// results in the rules "trim" and "downcase", in this order.
+type RuleFlag struct {
+	t *Transform
+}
+
+// Flag returns a flag.Value that adds string rules to the Transform.
+func (t *Transform) Flag() *RuleFlag {
+	return &RuleFlag{t: t}
+}
+
+// Set parses the given string rules and adds them (see AddStringRules).
+func (f *RuleFlag) Set(s string) error {
+	return f.t.AddStringRules(s)
+}
+
+// String returns the string rules added so far, separated by the rule
+// separator.
+func (f *RuleFlag) String() string {
+	if f == nil || f.t == nil {
+		return ""
+	}
+	return strings.Join(f.t.RuleStrings, f.t.ruleSeparator())
+}
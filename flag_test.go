@@ -0,0 +1,29 @@
+package transform
+
+import (
+	"flag"
+	"io"
+	"testing"
+)
+
+func TestRuleFlag(t *testing.T) {
+	y := New()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Var(y.Flag(), "transform", "transformation rules")
+	if err := fs.Parse([]string{"--transform", "trim", "--transform", "downcase,capitalize"}); err != nil {
+		t.Fatal(err)
+	}
+	if s, err := y.Transform(" hELLO "); err != nil || s != "Hello" {
+		t.Errorf("got %q, %v, want %q", s, err, "Hello")
+	}
+	if s := y.Flag().String(); s != "trim,downcase,capitalize" {
+		t.Errorf("got %q", s)
+	}
+	if err := fs.Parse([]string{"--transform", "nosuchrule"}); err == nil {
+		t.Error("expected error for unknown rule")
+	}
+	if s := (*RuleFlag)(nil).String(); s != "" {
+		t.Errorf("nil flag: got %q", s)
+	}
+}
@@ -2,4 +2,7 @@ module github.com/nmeilick/go-transform
 
 go 1.19
 
-require github.com/pkg/errors v0.9.1
+require (
+	github.com/pkg/errors v0.9.1
+	golang.org/x/text v0.14.0
+)
@@ -0,0 +1,73 @@
+package transform
+
+import (
+	"fmt"
+	"hash/crc32"
+	"hash/fnv"
+	"math"
+	"strconv"
+)
+
+// crc32cTable is the CRC-32 table for the Castagnoli polynomial.
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// CRC32 returns the CRC-32 checksum (IEEE polynomial) of the given string as
+// 8 hex digits.
+func (*Transform) CRC32(s string) (string, error) {
+	return fmt.Sprintf("%08x", crc32.ChecksumIEEE([]byte(s))), nil
+}
+
+// CRC32C returns the CRC-32 checksum (Castagnoli polynomial) of the given
+// string as 8 hex digits.
+func (*Transform) CRC32C(s string) (string, error) {
+	return fmt.Sprintf("%08x", crc32.Checksum([]byte(s), crc32cTable)), nil
+}
+
+// Bucket returns a function that maps its input to a bucket index in [0,n),
+// returned as a decimal string. The index is the 64-bit FNV-1a hash of the
+// UTF-8 bytes of the input modulo n, so it is stable across runs, machines
+// and implementations.
+func (*Transform) Bucket(n uint64) TransformFunc {
+	return func(s string) (string, error) {
+		h := fnv.New64a()
+		h.Write([]byte(s))
+		return strconv.FormatUint(h.Sum64()%n, 10), nil
+	}
+}
+
+// StringColor maps the string to a color in "#rrggbb" notation. The color is
+// derived from the 32-bit FNV-1a hash h of the UTF-8 bytes of the string, as
+// an HSL color with hue h mod 360, saturation 60 + (h/360 mod 21) percent and
+// lightness 45 + (h/7560 mod 16) percent, which avoids colors that are too
+// dark, too light or too gray. The conversion from HSL to RGB uses the
+// standard formula with components rounded to the nearest integer.
+func (*Transform) StringColor(s string) (string, error) {
+	f := fnv.New32a()
+	f.Write([]byte(s))
+	h := f.Sum32()
+
+	hue := float64(h % 360)
+	sat := float64(60+(h/360)%21) / 100
+	light := float64(45+(h/7560)%16) / 100
+
+	c := (1 - math.Abs(2*light-1)) * sat
+	x := c * (1 - math.Abs(math.Mod(hue/60, 2)-1))
+	m := light - c/2
+	var r, g, b float64
+	switch {
+	case hue < 60:
+		r, g, b = c, x, 0
+	case hue < 120:
+		r, g, b = x, c, 0
+	case hue < 180:
+		r, g, b = 0, c, x
+	case hue < 240:
+		r, g, b = 0, x, c
+	case hue < 300:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+	component := func(v float64) int { return int(math.Round((v + m) * 255)) }
+	return fmt.Sprintf("#%02x%02x%02x", component(r), component(g), component(b)), nil
+}
@@ -0,0 +1,67 @@
+package transform
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestCRC32(t *testing.T) {
+	tests := []struct {
+		rule, in, want string
+	}{
+		{"crc32", "", "00000000"},
+		{"crc32", "123456789", "cbf43926"},
+		{"crc32c", "", "00000000"},
+		{"crc32c", "123456789", "e3069283"},
+	}
+	y := New()
+	for _, tt := range tests {
+		if got, err := y.Apply(tt.rule, tt.in); err != nil || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestBucket(t *testing.T) {
+	tests := []struct {
+		rule, in, want string
+	}{
+		{"bucket:1", "anything", "0"},
+		{"bucket:10", "", "7"},
+		{"bucket:10", "a", "6"},
+		{"bucket:1000", "user-42", "419"},
+	}
+	y := New()
+	for _, tt := range tests {
+		if got, err := applyRule(y, tt.rule, tt.in); err != nil || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+	for _, rule := range []string{"bucket", "bucket:0", "bucket:-1", "bucket:x"} {
+		if _, err := y.ParseStringRule(rule); err == nil {
+			t.Errorf("%s: expected error", rule)
+		}
+	}
+}
+
+func TestStringColor(t *testing.T) {
+	y := New()
+	hex := regexp.MustCompile(`^#[0-9a-f]{6}$`)
+	seen := map[string]bool{}
+	for _, in := range []string{"", "alice", "bob", "carol", "日本"} {
+		got, err := applyRule(y, "stringcolor", in)
+		if err != nil || !hex.MatchString(got) {
+			t.Errorf("stringcolor(%q) = %q, %v", in, got, err)
+		}
+		if again, _ := applyRule(y, "stringcolor", in); again != got {
+			t.Errorf("stringcolor(%q) is not stable: %q, %q", in, got, again)
+		}
+		seen[got] = true
+	}
+	if len(seen) < 4 {
+		t.Errorf("got too few distinct colors: %v", seen)
+	}
+	if got, _ := applyRule(y, "stringcolor", "alice"); got != "#2124c4" {
+		t.Errorf("got %q, want %q", got, "#2124c4")
+	}
+}
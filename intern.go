@@ -0,0 +1,43 @@
+package transform
+
+import (
+	"sync"
+)
+
+// interner deduplicates strings so that equal strings share the same
+// backing memory.
+type interner struct {
+	mu sync.RWMutex
+	m  map[string]string
+}
+
+// intern returns the canonical instance of the given string.
+func (i *interner) intern(s string) string {
+	i.mu.RLock()
+	v, ok := i.m[s]
+	i.mu.RUnlock()
+	if ok {
+		return v
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if v, ok := i.m[s]; ok {
+		return v
+	}
+	i.m[s] = s
+	return s
+}
+
+// InternLookups returns an option func that makes Expand intern results that
+// consist of a single lookup value, e.g. the expansion of "${HOST}", in a
+// concurrency-safe table of the Transform, so that identical values kept by
+// the caller share one backing string. Other results are built from copies
+// of the lookup values and are not interned. Interned strings are released
+// only with the Transform, so the set of distinct lookup values should be
+// bounded.
+func InternLookups() TransformOption {
+	return func(t *Transform) {
+		t.interner = &interner{m: map[string]string{}}
+	}
+}
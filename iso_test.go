@@ -0,0 +1,28 @@
+package transform
+
+import (
+	"testing"
+)
+
+func TestISOCodes(t *testing.T) {
+	tests := []struct {
+		rule, in, want string
+		ok             bool
+	}{
+		{"iso3166", "de", "DE", true},
+		{"iso3166", " US ", "US", true},
+		{"iso3166", "UK", "", false},
+		{"iso3166", "DEU", "", false},
+		{"iso4217", "eur", "EUR", true},
+		{"iso4217", "XAU", "XAU", true},
+		{"iso4217", "DEM", "", false},
+		{"iso4217", "EU", "", false},
+	}
+	y := New()
+	for _, tt := range tests {
+		got, err := applyRule(y, tt.rule, tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+}
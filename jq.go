@@ -0,0 +1,425 @@
+package transform
+
+import (
+	"encoding/json"
+	"io"
+	"math/big"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// jqFilter maps an input value to zero or more output values.
+type jqFilter func(v interface{}) ([]interface{}, error)
+
+// jqCondRE matches a select condition of the form "<path> <op> <literal>".
+var jqCondRE = regexp.MustCompile(`^(\.\S*)\s*(==|!=|<=|>=|<|>)\s*(.+)$`)
+
+// JQ returns a function that applies a jq-like expression to JSON input. The
+// supported subset is:
+//
+//	.              identity
+//	.a.b           object field access (also .["a b"] for arbitrary keys)
+//	.[N]           array index, negative indices count from the end
+//	.[]            iteration over array elements or object values
+//	f | g          pipe
+//	length         length of a string, array or object
+//	keys           sorted keys of an object
+//	select(c)      passes values for which c holds, where c is a path that
+//	               must be truthy or "<path> <op> <literal>" with op one of
+//	               == != < <= > >= and a JSON literal
+//
+// Each result is emitted on its own line, strings unquoted and all other
+// values as compact JSON. Accessing missing fields yields null, as in jq.
+// Numbers are kept as written in the input, so large integers are not
+// rounded, and are compared by their exact value.
+func (*Transform) JQ(expr string) (TransformFunc, error) {
+	var filters []jqFilter
+	for _, stage := range splitTopLevel(expr, '|') {
+		f, err := parseJQStage(strings.TrimSpace(stage))
+		if err != nil {
+			return nil, errors.Wrap(err, "jq")
+		}
+		filters = append(filters, f)
+	}
+
+	return func(s string) (string, error) {
+		d := json.NewDecoder(strings.NewReader(s))
+		d.UseNumber()
+		var v interface{}
+		if err := d.Decode(&v); err != nil {
+			return "", errors.Wrap(err, "jq: invalid JSON")
+		}
+		if _, err := d.Token(); err != io.EOF {
+			return "", errors.New("jq: unexpected data after JSON value")
+		}
+		vals := []interface{}{v}
+		for _, f := range filters {
+			var next []interface{}
+			for _, v := range vals {
+				out, err := f(v)
+				if err != nil {
+					return "", errors.Wrap(err, "jq")
+				}
+				next = append(next, out...)
+			}
+			vals = next
+		}
+
+		lines := make([]string, len(vals))
+		for i, v := range vals {
+			str, err := jsonString(v)
+			if err != nil {
+				return "", errors.Wrap(err, "jq")
+			}
+			if v == nil {
+				str = "null"
+			}
+			lines[i] = str
+		}
+		return strings.Join(lines, "\n"), nil
+	}, nil
+}
+
+// splitTopLevel splits s at sep, ignoring separators inside quotes, brackets
+// and parentheses.
+func splitTopLevel(s string, sep byte) []string {
+	var parts []string
+	depth, start := 0, 0
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quoted:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				quoted = false
+			}
+		case c == '"':
+			quoted = true
+		case c == '(' || c == '[':
+			depth++
+		case c == ')' || c == ']':
+			depth--
+		case c == sep && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// parseJQStage parses a single pipe stage.
+func parseJQStage(s string) (jqFilter, error) {
+	switch {
+	case s == "length":
+		return jqLength, nil
+	case s == "keys":
+		return jqKeys, nil
+	case strings.HasPrefix(s, "select(") && strings.HasSuffix(s, ")"):
+		return parseJQSelect(strings.TrimSpace(s[7 : len(s)-1]))
+	case strings.HasPrefix(s, "."):
+		return parseJQPath(s)
+	}
+	return nil, errors.New("unsupported expression: " + s)
+}
+
+// parseJQPath parses a path expression like ".a[0].b".
+func parseJQPath(s string) (jqFilter, error) {
+	var steps []jqFilter
+	p := s
+	if p == "." {
+		p = ""
+	}
+	for p != "" {
+		switch {
+		case strings.HasPrefix(p, ".["):
+			p = p[1:]
+		case strings.HasPrefix(p, "."):
+			i := 1
+			for i < len(p) && (p[i] == '_' || 'a' <= p[i] && p[i] <= 'z' || 'A' <= p[i] && p[i] <= 'Z' || i > 1 && '0' <= p[i] && p[i] <= '9') {
+				i++
+			}
+			if i == 1 {
+				return nil, errors.New("invalid path: " + s)
+			}
+			steps = append(steps, jqField(p[1:i]))
+			p = p[i:]
+		case strings.HasPrefix(p, "["):
+			end := jqBracketEnd(p)
+			if end == -1 {
+				return nil, errors.New("invalid path: " + s)
+			}
+			arg := strings.TrimSpace(p[1:end])
+			switch {
+			case arg == "":
+				steps = append(steps, jqIterate)
+			case strings.HasPrefix(arg, `"`):
+				key, err := strconv.Unquote(arg)
+				if err != nil {
+					return nil, errors.New("invalid key: " + arg)
+				}
+				steps = append(steps, jqField(key))
+			default:
+				n, err := strconv.Atoi(arg)
+				if err != nil {
+					return nil, errors.New("invalid index: " + arg)
+				}
+				steps = append(steps, jqIndex(n))
+			}
+			p = p[end+1:]
+		default:
+			return nil, errors.New("invalid path: " + s)
+		}
+	}
+
+	return func(v interface{}) ([]interface{}, error) {
+		vals := []interface{}{v}
+		for _, step := range steps {
+			var next []interface{}
+			for _, v := range vals {
+				out, err := step(v)
+				if err != nil {
+					return nil, err
+				}
+				next = append(next, out...)
+			}
+			vals = next
+		}
+		return vals, nil
+	}, nil
+}
+
+// jqBracketEnd returns the index of the "]" closing the bracket at the start
+// of p, skipping a quoted key that may contain brackets itself, or -1.
+func jqBracketEnd(p string) int {
+	quoted := false
+	for i := 1; i < len(p); i++ {
+		switch c := p[i]; {
+		case quoted:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				quoted = false
+			}
+		case c == '"':
+			quoted = true
+		case c == ']':
+			return i
+		}
+	}
+	return -1
+}
+
+// jqField returns a filter that accesses an object field.
+func jqField(key string) jqFilter {
+	return func(v interface{}) ([]interface{}, error) {
+		switch v := v.(type) {
+		case nil:
+			return []interface{}{nil}, nil
+		case map[string]interface{}:
+			return []interface{}{v[key]}, nil
+		}
+		return nil, errors.New("cannot access field of non-object: " + key)
+	}
+}
+
+// jqIndex returns a filter that accesses an array element.
+func jqIndex(n int) jqFilter {
+	return func(v interface{}) ([]interface{}, error) {
+		switch v := v.(type) {
+		case nil:
+			return []interface{}{nil}, nil
+		case []interface{}:
+			i := n
+			if i < 0 {
+				i += len(v)
+			}
+			if i < 0 || i >= len(v) {
+				return []interface{}{nil}, nil
+			}
+			return []interface{}{v[i]}, nil
+		}
+		return nil, errors.New("cannot index non-array")
+	}
+}
+
+// jqIterate returns the elements of an array or the values of an object.
+func jqIterate(v interface{}) ([]interface{}, error) {
+	switch v := v.(type) {
+	case []interface{}:
+		return v, nil
+	case map[string]interface{}:
+		keys := sortedKeys(v)
+		vals := make([]interface{}, len(keys))
+		for i, k := range keys {
+			vals[i] = v[k]
+		}
+		return vals, nil
+	}
+	return nil, errors.New("cannot iterate over non-container")
+}
+
+// jqLength returns the length of a string, array or object.
+func jqLength(v interface{}) ([]interface{}, error) {
+	n := 0
+	switch v := v.(type) {
+	case nil:
+	case string:
+		n = len([]rune(v))
+	case []interface{}:
+		n = len(v)
+	case map[string]interface{}:
+		n = len(v)
+	default:
+		return nil, errors.New("value has no length")
+	}
+	return []interface{}{json.Number(strconv.Itoa(n))}, nil
+}
+
+// jqKeys returns the sorted keys of an object.
+func jqKeys(v interface{}) ([]interface{}, error) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("cannot get keys of non-object")
+	}
+	var keys []interface{}
+	for _, k := range sortedKeys(m) {
+		keys = append(keys, k)
+	}
+	return []interface{}{keys}, nil
+}
+
+// sortedKeys returns the keys of a map in sorted order.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// parseJQSelect parses the condition of a select filter.
+func parseJQSelect(cond string) (jqFilter, error) {
+	m := jqCondRE.FindStringSubmatch(cond)
+	if m == nil {
+		path, err := parseJQPath(cond)
+		if err != nil {
+			return nil, err
+		}
+		return jqSelect(path, func(v interface{}) bool {
+			return v != nil && v != false
+		}), nil
+	}
+
+	path, err := parseJQPath(m[1])
+	if err != nil {
+		return nil, err
+	}
+	d := json.NewDecoder(strings.NewReader(m[3]))
+	d.UseNumber()
+	var lit interface{}
+	if err := d.Decode(&lit); err != nil {
+		return nil, errors.New("invalid literal: " + m[3])
+	}
+	if _, err := d.Token(); err != io.EOF {
+		return nil, errors.New("invalid literal: " + m[3])
+	}
+	op := m[2]
+	return jqSelect(path, func(v interface{}) bool {
+		switch op {
+		case "==":
+			return jqEqual(v, lit)
+		case "!=":
+			return !jqEqual(v, lit)
+		}
+		c, ok := jqCompare(v, lit)
+		if !ok {
+			return false
+		}
+		switch op {
+		case "<":
+			return c < 0
+		case "<=":
+			return c <= 0
+		case ">":
+			return c > 0
+		}
+		return c >= 0
+	}), nil
+}
+
+// jqSelect returns a filter that passes values for which the predicate holds
+// for any of the values selected by the path.
+func jqSelect(path jqFilter, pred func(interface{}) bool) jqFilter {
+	return func(v interface{}) ([]interface{}, error) {
+		vals, err := path(v)
+		if err != nil {
+			return nil, err
+		}
+		for _, x := range vals {
+			if pred(x) {
+				return []interface{}{v}, nil
+			}
+		}
+		return nil, nil
+	}
+}
+
+// jqEqual reports whether two values are equal, comparing numbers by value,
+// also within arrays and objects.
+func jqEqual(a, b interface{}) bool {
+	switch a := a.(type) {
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !jqEqual(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, v := range a {
+			w, ok := b[k]
+			if !ok || !jqEqual(v, w) {
+				return false
+			}
+		}
+		return true
+	}
+	if c, ok := jqCompare(a, b); ok {
+		return c == 0
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// jqCompare compares two numbers by their exact value, or two strings.
+func jqCompare(a, b interface{}) (int, bool) {
+	switch a := a.(type) {
+	case json.Number:
+		if b, ok := b.(json.Number); ok {
+			x, okx := new(big.Rat).SetString(string(a))
+			y, oky := new(big.Rat).SetString(string(b))
+			if okx && oky {
+				return x.Cmp(y), true
+			}
+		}
+	case string:
+		if b, ok := b.(string); ok {
+			return strings.Compare(a, b), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,55 @@
+package transform
+
+import (
+	"testing"
+)
+
+func TestJQ(t *testing.T) {
+	tests := []struct {
+		expr, in, want string
+	}{
+		{".a", `{"a":9007199254740993}`, "9007199254740993"},
+		{".a", `{"a":1.50}`, "1.50"},
+		{".[] | select(.id == 9007199254740993) | .n", `[{"id":9007199254740992,"n":"x"},{"id":9007199254740993,"n":"y"}]`, "y"},
+		{".[] | select(.v == 1) | .n", `[{"v":1.0,"n":"a"},{"v":2,"n":"b"}]`, "a"},
+		{".[] | select(.v > 1e1) | .n", `[{"v":10,"n":"a"},{"v":11,"n":"b"}]`, "b"},
+		{".a | length", `{"a":[1,2,3]}`, "3"},
+		{".s | length", `{"s":"héllo"}`, "5"},
+		{"keys", `{"b":1,"a":2}`, `["a","b"]`},
+		{`.["a]b"]`, `{"a]b":"x"}`, "x"},
+		{`.["a\"]"].c`, `{"a\"]":{"c":1}}`, "1"},
+		{`.["x"][1]`, `{"x":[1,2]}`, "2"},
+		{".[] | select(.v == [1,{\"a\":2}]) | .n", `[{"v":[1.0,{"a":2e0}],"n":"a"},{"v":[1,{"a":3}],"n":"b"},{"v":[1],"n":"c"}]`, "a"},
+		{".[] | select(.v != {\"a\":[1]}) | .n", `[{"v":{"a":[1.0]},"n":"a"},{"v":{"b":[1]},"n":"b"}]`, "b"},
+		{".a", `{"a":{"b":[1,true,null]}}`, `{"b":[1,true,null]}`},
+	}
+	y := New()
+	for _, tt := range tests {
+		f, err := y.JQ(tt.expr)
+		if err != nil {
+			t.Errorf("JQ(%q): %v", tt.expr, err)
+			continue
+		}
+		if got, err := f(tt.in); err != nil || got != tt.want {
+			t.Errorf("JQ(%q)(%q) = %q, %v, want %q", tt.expr, tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestJQErrors(t *testing.T) {
+	y := New()
+	for _, expr := range []string{"", ".[", `.["a]`, ".[x]", "foo", `.["a"`} {
+		if _, err := y.JQ(expr); err == nil {
+			t.Errorf("JQ(%q): expected error", expr)
+		}
+	}
+	f, err := y.JQ(".a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, in := range []string{"{", `{"a":1} x`, "[1]"} {
+		if _, err := f(in); err == nil {
+			t.Errorf("JQ(.a)(%q): expected error", in)
+		}
+	}
+}
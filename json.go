@@ -0,0 +1,260 @@
+package transform
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// JSONRequire returns a function that ensures that the input is a JSON object
+// containing all the given top-level keys, and returns the input unchanged.
+func (*Transform) JSONRequire(keys ...string) TransformFunc {
+	return func(s string) (string, error) {
+		var m map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(s), &m); err != nil {
+			return "", errors.Wrap(err, "jsonrequire: invalid JSON object")
+		}
+		if m == nil {
+			return "", errors.New("jsonrequire: not a JSON object")
+		}
+		var missing []string
+		for _, k := range keys {
+			if _, ok := m[k]; !ok {
+				missing = append(missing, k)
+			}
+		}
+		if len(missing) > 0 {
+			return "", errors.New("jsonrequire: missing keys: " + strings.Join(missing, ", "))
+		}
+		return s, nil
+	}
+}
+
+// JSONGet returns a function that parses the input as JSON and returns the
+// value at the given dotted path, e.g. "a.0.b", where numeric segments index
+// arrays. Strings are returned unquoted, null as an empty string and objects
+// and arrays as compact JSON. If the path does not exist, an empty string is
+// returned if allowMissing is true, or an error otherwise.
+func (*Transform) JSONGet(path string, allowMissing bool) TransformFunc {
+	var segments []string
+	if path != "" {
+		segments = strings.Split(path, ".")
+	}
+
+	return func(s string) (string, error) {
+		d := json.NewDecoder(strings.NewReader(s))
+		d.UseNumber()
+		var v interface{}
+		if err := d.Decode(&v); err != nil {
+			return "", errors.Wrap(err, "jsonget: invalid JSON")
+		}
+		if _, err := d.Token(); err != io.EOF {
+			return "", errors.New("jsonget: unexpected data after JSON value")
+		}
+
+		for _, seg := range segments {
+			var ok bool
+			switch c := v.(type) {
+			case map[string]interface{}:
+				v, ok = c[seg]
+			case []interface{}:
+				var i int
+				if i, ok = jsonIndex(seg, len(c)); ok {
+					v = c[i]
+				}
+			}
+			if !ok {
+				if allowMissing {
+					return "", nil
+				}
+				return "", errors.New("jsonget: path not found: " + path)
+			}
+		}
+		return jsonString(v)
+	}
+}
+
+// jsonIndex parses an array index and checks it against the array length.
+func jsonIndex(s string, n int) (int, bool) {
+	i, err := strconv.Atoi(s)
+	if err != nil || i < 0 || i >= n {
+		return 0, false
+	}
+	return i, true
+}
+
+// jsonString returns the string representation of a decoded JSON value.
+func jsonString(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// PathNorm returns a function that parses a field path in dotted ("a.b.0")
+// or JSON pointer ("/a/b/0") notation and returns it in dotted notation, or
+// in JSON pointer notation if pointer is true. Malformed paths, e.g. with
+// invalid pointer escapes, result in an error, as do segments that cannot be
+// represented in dotted notation, e.g. empty ones. Empty segments of a JSON
+// pointer are kept in pointer notation, e.g. "/" refers to the key "".
+func (*Transform) PathNorm(pointer bool) TransformFunc {
+	return func(s string) (string, error) {
+		var segments []string
+		isPointer := strings.HasPrefix(s, "/")
+		if isPointer {
+			for _, seg := range strings.Split(s[1:], "/") {
+				if strings.Contains(strings.NewReplacer("~0", "", "~1", "").Replace(seg), "~") {
+					return "", errors.New("pathnorm: invalid escape in JSON pointer: " + s)
+				}
+				segments = append(segments, strings.NewReplacer("~1", "/", "~0", "~").Replace(seg))
+			}
+		} else if s != "" {
+			segments = strings.Split(s, ".")
+		}
+
+		// Empty segments are valid in JSON pointers, where they refer to
+		// the key "", but cannot be represented in dotted notation.
+		if !isPointer || !pointer {
+			for _, seg := range segments {
+				if seg == "" {
+					return "", errors.New("pathnorm: empty path segment: " + s)
+				}
+			}
+		}
+
+		if pointer {
+			var b strings.Builder
+			for _, seg := range segments {
+				b.WriteByte('/')
+				b.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(seg))
+			}
+			return b.String(), nil
+		}
+		for _, seg := range segments {
+			if strings.Contains(seg, ".") {
+				return "", errors.New("pathnorm: segment cannot be represented in dotted notation: " + seg)
+			}
+		}
+		return strings.Join(segments, "."), nil
+	}
+}
+
+// JSONCanon parses the input as JSON and returns it in a canonical compact
+// form with object keys sorted recursively. Array order is preserved, and
+// numbers are written in a canonical form (see canonNumber), so that e.g.
+// 1.0, 1e0 and 1 all become 1. HTML characters are not escaped.
+func (*Transform) JSONCanon(s string) (string, error) {
+	d := json.NewDecoder(strings.NewReader(s))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return "", errors.Wrap(err, "jsoncanon: invalid JSON")
+	}
+	if _, err := d.Token(); err != io.EOF {
+		return "", errors.New("jsoncanon: unexpected data after JSON value")
+	}
+
+	var b bytes.Buffer
+	e := json.NewEncoder(&b)
+	e.SetEscapeHTML(false)
+	if err := e.Encode(canonNumbers(v)); err != nil {
+		return "", errors.Wrap(err, "jsoncanon")
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// canonNumbers replaces all numbers in a decoded JSON value by their
+// canonical form.
+func canonNumbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		return canonNumber(v)
+	case []interface{}:
+		for i := range v {
+			v[i] = canonNumbers(v[i])
+		}
+	case map[string]interface{}:
+		for k := range v {
+			v[k] = canonNumbers(v[k])
+		}
+	}
+	return v
+}
+
+// canonNumber returns the shortest exact representation of a JSON number,
+// following the layout of ECMAScript number serialization as used by RFC
+// 8785, but without rounding to a float64: numbers whose decimal point falls
+// within 21 digits are written without exponent, e.g. "100" for 1e2 or
+// "0.0001" for 1e-4, and others in exponential notation, e.g. "1e+21" or
+// "1.5e-7". Negative zero becomes "0".
+func canonNumber(n json.Number) json.Number {
+	s := string(n)
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	exp := 0
+	if i := strings.IndexAny(s, "eE"); i != -1 {
+		e, err := strconv.Atoi(s[i+1:])
+		if err != nil {
+			return n
+		}
+		exp, s = e, s[:i]
+	}
+	if i := strings.IndexByte(s, '.'); i != -1 {
+		exp -= len(s) - i - 1
+		s = s[:i] + s[i+1:]
+	}
+
+	// The value is now s * 10^exp, normalize s to be free of leading and
+	// trailing zeros.
+	s = strings.TrimLeft(s, "0")
+	if s == "" {
+		return "0"
+	}
+	k := len(s)
+	s = strings.TrimRight(s, "0")
+	exp += k - len(s)
+	k = len(s)
+
+	// pos is the position of the decimal point relative to the start of s.
+	pos := exp + k
+	var res string
+	switch {
+	case k <= pos && pos <= 21:
+		res = s + strings.Repeat("0", pos-k)
+	case 0 < pos && pos <= 21:
+		res = s[:pos] + "." + s[pos:]
+	case -6 < pos && pos <= 0:
+		res = "0." + strings.Repeat("0", -pos) + s
+	default:
+		res = s[:1]
+		if k > 1 {
+			res += "." + s[1:]
+		}
+		e := pos - 1
+		if e >= 0 {
+			res += "e+" + strconv.Itoa(e)
+		} else {
+			res += "e-" + strconv.Itoa(-e)
+		}
+	}
+	if neg {
+		res = "-" + res
+	}
+	return json.Number(res)
+}
@@ -0,0 +1,105 @@
+package transform
+
+import (
+	"testing"
+)
+
+func TestJSONRequire(t *testing.T) {
+	tests := []struct {
+		rule, in string
+		ok       bool
+	}{
+		{"jsonrequire:a,b", `{"a":1,"b":null}`, true},
+		{"jsonrequire:a:b", `{"a":1,"b":2,"c":3}`, true},
+		{"jsonrequire:a,b", `{"a":1}`, false},
+		{"jsonrequire:a", `[1]`, false},
+		{"jsonrequire:a", `null`, false},
+		{"jsonrequire:a", `{"a":`, false},
+	}
+	y := New()
+	for _, tt := range tests {
+		got, err := applyRule(y, tt.rule, tt.in)
+		if (err == nil) != tt.ok || tt.ok && got != tt.in {
+			t.Errorf("%s(%q) = %q, %v", tt.rule, tt.in, got, err)
+		}
+	}
+	if _, err := y.ParseStringRule("jsonrequire"); err == nil {
+		t.Error("expected error without keys")
+	}
+}
+
+func TestJSONGet(t *testing.T) {
+	tests := []struct {
+		path, in, want string
+		ok             bool
+	}{
+		{"a", `{"a":1}`, "1", true},
+		{"a.1.b", `{"a":[0,{"b":"x"}]}`, "x", true},
+		{"a", ` {"a":12345678901234567890} `, "12345678901234567890", true},
+		{"a", `{"a":1} garbage`, "", false},
+		{"a", `{"a":1} {}`, "", false},
+		{"b", `{"a":1}`, "", false},
+	}
+	y := New()
+	for _, tt := range tests {
+		got, err := y.JSONGet(tt.path, false)(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("JSONGet(%q)(%q) = %q, %v, want %q", tt.path, tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestPathNorm(t *testing.T) {
+	tests := []struct {
+		pointer bool
+		in      string
+		want    string
+		ok      bool
+	}{
+		{false, "/a/b/0", "a.b.0", true},
+		{true, "a.b.0", "/a/b/0", true},
+		{true, "/a~1b/c~0d", "/a~1b/c~0d", true},
+		{true, "", "", true},
+		{true, "/", "/", true},
+		{true, "/a//b", "/a//b", true},
+		{false, "/", "", false},
+		{false, "a..b", "", false},
+		{true, "a..b", "", false},
+		{true, "/a~2", "", false},
+		{false, "/a.b", "", false},
+	}
+	y := New()
+	for _, tt := range tests {
+		got, err := y.PathNorm(tt.pointer)(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("PathNorm(%v)(%q) = %q, %v, want %q", tt.pointer, tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestJSONCanon(t *testing.T) {
+	tests := []struct {
+		in, want string
+		ok       bool
+	}{
+		{`{"b":1,"a":{"d":[3,1],"c":null}}`, `{"a":{"c":null,"d":[3,1]},"b":1}`, true},
+		{` "<&>" `, `"<&>"`, true},
+		{`{"a":1.0}`, `{"a":1}`, true},
+		{`[1, 1.0, 1e0, 10E-1, 0.1e1]`, `[1,1,1,1,1]`, true},
+		{`[1e2, 100, 100.00]`, `[100,100,100]`, true},
+		{`[-0, 0.0, -0e5]`, `[0,0,0]`, true},
+		{`[0.5, 5e-1, 0.0001, 1e-7, 1.5e-7]`, `[0.5,0.5,0.0001,1e-7,1.5e-7]`, true},
+		{`[1e21, 1e20, 123e30, -2.50e-3]`, `[1e+21,100000000000000000000,1.23e+32,-0.0025]`, true},
+		{`12345678901234567890123`, `1.2345678901234567890123e+22`, true},
+		{`9007199254740993`, `9007199254740993`, true},
+		{`{"a":1} x`, "", false},
+		{`{`, "", false},
+	}
+	y := New()
+	for _, tt := range tests {
+		got, err := y.JSONCanon(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("JSONCanon(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package transform
+
+import (
+	"sync"
+	"time"
+)
+
+// RuleMetric holds the number of invocations and the cumulative duration of a
+// rule.
+type RuleMetric struct {
+	Calls    int64
+	Duration time.Duration
+}
+
+// ruleMetrics collects metrics indexed by rule position.
+type ruleMetrics struct {
+	mu      sync.Mutex
+	metrics []RuleMetric
+}
+
+// record adds an invocation of the rule at index i that took duration d.
+func (m *ruleMetrics) record(i int, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if i >= len(m.metrics) {
+		m.metrics = append(m.metrics, make([]RuleMetric, i+1-len(m.metrics))...)
+	}
+	m.metrics[i].Calls++
+	m.metrics[i].Duration += d
+}
+
+// Metrics returns an option func that enables the collection of per-rule
+// metrics (see Transform.RuleMetrics).
+func Metrics() TransformOption {
+	return func(t *Transform) {
+		t.metrics = &ruleMetrics{}
+	}
+}
+
+// RuleMetrics returns a copy of the metrics collected so far, indexed by the
+// position of the rule in the applied chain, or nil if metrics are disabled.
+func (t *Transform) RuleMetrics() []RuleMetric {
+	if t.metrics == nil {
+		return nil
+	}
+	t.metrics.mu.Lock()
+	defer t.metrics.mu.Unlock()
+	return append([]RuleMetric(nil), t.metrics.metrics...)
+}
@@ -0,0 +1,28 @@
+package transform
+
+import (
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	if m := New().RuleMetrics(); m != nil {
+		t.Errorf("got %v without Metrics option", m)
+	}
+	y := New(Metrics())
+	if err := y.AddStringRules("trim,upcase"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := y.Transform(" a "); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m := y.RuleMetrics()
+	if len(m) != 2 || m[0].Calls != 3 || m[1].Calls != 3 {
+		t.Errorf("got %+v, want two rules called three times", m)
+	}
+	m[0].Calls = 0
+	if y.RuleMetrics()[0].Calls != 3 {
+		t.Error("RuleMetrics does not return a copy")
+	}
+}
@@ -0,0 +1,78 @@
+package transform
+
+import (
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// MAC validates a MAC-48 or EUI-64 address in colon, hyphen or dotted (Cisco)
+// notation and returns it in lowercase colon-separated form.
+func (*Transform) MAC(s string) (string, error) {
+	hw, err := net.ParseMAC(strings.TrimSpace(s))
+	if err != nil || (len(hw) != 6 && len(hw) != 8) {
+		return "", errors.New("invalid MAC address: " + s)
+	}
+	return hw.String(), nil
+}
+
+// EmailList returns a function that splits its input at sep, validates each
+// email address, lowercases its domain and joins the unique addresses using
+// outSep. Display names are dropped. Any invalid address results in an
+// error.
+func (*Transform) EmailList(sep, outSep string) TransformFunc {
+	return func(s string) (string, error) {
+		var addrs []string
+		seen := map[string]bool{}
+		for _, a := range strings.Split(s, sep) {
+			if a = strings.TrimSpace(a); a == "" {
+				continue
+			}
+			addr, err := mail.ParseAddress(a)
+			if err != nil {
+				return "", errors.Wrap(err, "emaillist: "+a)
+			}
+			email := addr.Address
+			if i := strings.LastIndexByte(email, '@'); i != -1 {
+				email = email[:i] + strings.ToLower(email[i:])
+			}
+			if !seen[email] {
+				seen[email] = true
+				addrs = append(addrs, email)
+			}
+		}
+		return strings.Join(addrs, outSep), nil
+	}
+}
+
+// Port returns a function that validates a port number in the range 1-65535,
+// or 0-65535 if allowZero is true, and returns it without leading zeros.
+func (*Transform) Port(allowZero bool) TransformFunc {
+	return func(s string) (string, error) {
+		p, err := strconv.ParseUint(strings.TrimSpace(s), 10, 16)
+		if err != nil || p == 0 && !allowZero {
+			return "", errors.New("invalid port: " + s)
+		}
+		return strconv.FormatUint(p, 10), nil
+	}
+}
+
+// EmailMask masks the local part of an email address for display, keeping
+// only its first character, e.g. "j***@example.com" for "john@example.com".
+// A one-character local part is masked completely, e.g. "*@example.com" for
+// "j@example.com", so the result always contains at least one "*". Strings
+// without "@" are returned unchanged.
+func (*Transform) EmailMask(s string) (string, error) {
+	i := strings.LastIndexByte(s, '@')
+	if i == -1 {
+		return s, nil
+	}
+	local := []rune(s[:i])
+	if len(local) <= 1 {
+		return "*" + s[i:], nil
+	}
+	return string(local[0]) + strings.Repeat("*", len(local)-1) + s[i:], nil
+}
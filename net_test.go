@@ -0,0 +1,95 @@
+package transform
+
+import (
+	"testing"
+)
+
+func TestMAC(t *testing.T) {
+	tests := []struct {
+		in, want string
+		ok       bool
+	}{
+		{"00:1A:2B:3C:4D:5E", "00:1a:2b:3c:4d:5e", true},
+		{"00-1a-2b-3c-4d-5e", "00:1a:2b:3c:4d:5e", true},
+		{" 001a.2b3c.4d5e ", "00:1a:2b:3c:4d:5e", true},
+		{"00:1a:2b:3c:4d:5e:6f:70", "00:1a:2b:3c:4d:5e:6f:70", true},
+		{"00:00:00:00:fe:80:00:00:00:00:00:00:02:00:5e:10:00:00:00:01", "", false},
+		{"00:1a:2b:3c:4d", "", false},
+		{"zz:1a:2b:3c:4d:5e", "", false},
+	}
+	y := New()
+	for _, tt := range tests {
+		got, err := applyRule(y, "mac", tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("mac(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestEmailList(t *testing.T) {
+	tests := []struct {
+		rule, in, want string
+		ok             bool
+	}{
+		{"emaillist", "a@Example.COM, John <j@x.org>,,A@example.com", "a@example.com, j@x.org, A@example.com", true},
+		{"emaillist", "a@x.org,a@X.org", "a@x.org", true},
+		{`emaillist:;:\n`, "a@x.org; b@y.org", "a@x.org\nb@y.org", true},
+		{"emaillist", "", "", true},
+		{"emaillist", "a@x.org, not an address", "", false},
+	}
+	y := New()
+	for _, tt := range tests {
+		got, err := applyRule(y, tt.rule, tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := y.ParseStringRule(`emaillist:\q`); err == nil {
+		t.Error("expected error for invalid escape sequence")
+	}
+}
+
+func TestPort(t *testing.T) {
+	tests := []struct {
+		rule, in, want string
+		ok             bool
+	}{
+		{"port", "8080", "8080", true},
+		{"port", " 0443 ", "443", true},
+		{"port", "65535", "65535", true},
+		{"port", "65536", "", false},
+		{"port", "0", "", false},
+		{"port:allowzero", "0", "0", true},
+		{"port", "-1", "", false},
+		{"port", "http", "", false},
+	}
+	y := New()
+	for _, tt := range tests {
+		got, err := applyRule(y, tt.rule, tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := y.ParseStringRule("port:x"); err == nil {
+		t.Error("expected error for invalid mode")
+	}
+}
+
+func TestEmailMask(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"john@example.com", "j***@example.com"},
+		{"jo@example.com", "j*@example.com"},
+		{"j@example.com", "*@example.com"},
+		{"@example.com", "*@example.com"},
+		{"jürgen@example.com", "j*****@example.com"},
+		{"not an email", "not an email"},
+	}
+	y := New()
+	for _, tt := range tests {
+		if got, err := y.EmailMask(tt.in); err != nil || got != tt.want {
+			t.Errorf("EmailMask(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package transform
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// Percent returns a function that parses a ratio like "3/4" and returns it as
+// a percentage with the given number of decimal places, e.g. "75%". The
+// percent sign is omitted if sign is false. Ratios that are not finite, e.g.
+// "inf/1", result in an error.
+func (*Transform) Percent(decimals int, sign bool) TransformFunc {
+	return func(s string) (string, error) {
+		xs, ys, ok := strings.Cut(s, "/")
+		if !ok {
+			return "", errors.New("percent: expected ratio x/y: " + s)
+		}
+		x, err := strconv.ParseFloat(strings.TrimSpace(xs), 64)
+		if err != nil {
+			return "", errors.Wrap(err, "percent")
+		}
+		y, err := strconv.ParseFloat(strings.TrimSpace(ys), 64)
+		if err != nil {
+			return "", errors.Wrap(err, "percent")
+		}
+		if y == 0 {
+			return "", errors.New("percent: division by zero: " + s)
+		}
+		p := x / y * 100
+		if math.IsNaN(p) || math.IsInf(p, 0) {
+			return "", errors.New("percent: not a finite ratio: " + s)
+		}
+		r := strconv.FormatFloat(p, 'f', decimals, 64)
+		if sign {
+			r += "%"
+		}
+		return r, nil
+	}
+}
+
+// Ordinal returns an integer with its English ordinal suffix, e.g. "1st",
+// "22nd" or "13th". Non-integer input results in an error.
+func (*Transform) Ordinal(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return "", errors.New("ordinal: not an integer: " + s)
+	}
+	if n < 0 {
+		n = -n
+	}
+	suffix := "th"
+	if n%100 < 11 || n%100 > 13 {
+		switch n % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return s + suffix, nil
+}
+
+// FixedDec returns a function that parses a number and formats it with
+// exactly the given number of decimal places, e.g. "3.00" for "3". The value
+// is rounded to the nearest representation based on its exact binary
+// floating-point value, so "2.675" becomes "2.67" as 2.675 is stored as
+// 2.67499999...; exact ties are rounded to even.
+func (*Transform) FixedDec(decimals int) TransformFunc {
+	return func(s string) (string, error) {
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			return "", errors.New("fixeddec: not a number: " + s)
+		}
+		return strconv.FormatFloat(f, 'f', decimals, 64), nil
+	}
+}
+
+// maxRangeItems limits the number of items produced by Range.
+const maxRangeItems = 100000
+
+// Range returns a function that expands a range spec like "1-5", "a-e" or
+// "1-10:2" (with a step) into a list joined by sep, e.g. "1,2,3,4,5".
+// Bounds are integers or single letters of the same case. Descending ranges
+// require a negative step.
+func (*Transform) Range(sep string) TransformFunc {
+	return func(s string) (string, error) {
+		spec := strings.TrimSpace(s)
+		step := int64(1)
+		if i := strings.IndexByte(spec, ':'); i != -1 {
+			n, err := strconv.ParseInt(strings.TrimSpace(spec[i+1:]), 10, 64)
+			if err != nil || n == 0 {
+				return "", errors.New("range: invalid step: " + s)
+			}
+			step = n
+			spec = spec[:i]
+		}
+
+		// Skip the first character when looking for the separating dash,
+		// as it may be the sign of a negative start value.
+		i := -1
+		if spec != "" {
+			if i = strings.IndexByte(spec[1:], '-'); i != -1 {
+				i++
+			}
+		}
+		if i == -1 {
+			return "", errors.New("range: expected start-end: " + s)
+		}
+		from, to := strings.TrimSpace(spec[:i]), strings.TrimSpace(spec[i+1:])
+
+		letters := false
+		var a, b int64
+		if isLetter(from) && isLetter(to) {
+			if unicode.IsUpper(rune(from[0])) != unicode.IsUpper(rune(to[0])) {
+				return "", errors.New("range: mixed case letters: " + s)
+			}
+			letters = true
+			a, b = int64(from[0]), int64(to[0])
+		} else {
+			var err1, err2 error
+			a, err1 = strconv.ParseInt(from, 10, 64)
+			b, err2 = strconv.ParseInt(to, 10, 64)
+			if err1 != nil || err2 != nil {
+				return "", errors.New("range: invalid bounds: " + s)
+			}
+		}
+		if (b < a) != (step < 0) && a != b {
+			return "", errors.New("range: step does not lead from start to end: " + s)
+		}
+
+		var items []string
+		for v := a; ; v += step {
+			if len(items) == maxRangeItems {
+				return "", errors.New("range: too many items: " + s)
+			}
+			if letters {
+				items = append(items, string(rune(v)))
+			} else {
+				items = append(items, strconv.FormatInt(v, 10))
+			}
+			// Stop before the next step would pass the end. The distance
+			// is computed unsigned, as it may exceed the int64 range.
+			if step > 0 && uint64(b)-uint64(v) < uint64(step) ||
+				step < 0 && uint64(v)-uint64(b) < uint64(-step) {
+				break
+			}
+		}
+		return strings.Join(items, sep), nil
+	}
+}
+
+// isLetter reports whether s consists of a single ASCII letter.
+func isLetter(s string) bool {
+	return len(s) == 1 && ('a' <= s[0] && s[0] <= 'z' || 'A' <= s[0] && s[0] <= 'Z')
+}
+
+// Plural returns a function that selects one of two templates depending on
+// the numeric input and replaces "{n}" in it with the number. Following the
+// English CLDR plural rule, the "one" template is used if the absolute value
+// of the number is 1 and it is written without a decimal point, e.g. "1" or
+// "-1" but not "1.0", and the "other" template otherwise. The input must be a
+// finite decimal number.
+func (*Transform) Plural(one, other string) TransformFunc {
+	return func(s string) (string, error) {
+		n := strings.TrimSpace(s)
+		f, err := strconv.ParseFloat(n, 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) || strings.ContainsAny(n, "xX") {
+			return "", errors.New("plural: not a number: " + s)
+		}
+		tmpl := other
+		if math.Abs(f) == 1 && !strings.Contains(n, ".") {
+			tmpl = one
+		}
+		return strings.ReplaceAll(tmpl, "{n}", n), nil
+	}
+}
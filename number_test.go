@@ -0,0 +1,144 @@
+package transform
+
+import (
+	"testing"
+)
+
+func TestPercent(t *testing.T) {
+	tests := []struct {
+		rule, in, want string
+		ok             bool
+	}{
+		{"percent", "3/4", "75%", true},
+		{"percent:1", " 1 / 3 ", "33.3%", true},
+		{"percent:2:nosign", "1/8", "12.50", true},
+		{"percent", "-1/2", "-50%", true},
+		{"percent", "1/0", "", false},
+		{"percent", "3", "", false},
+		{"percent", "a/4", "", false},
+		{"percent", "inf/1", "", false},
+		{"percent", "NaN/1", "", false},
+		{"percent", "1e308/1e-308", "", false},
+	}
+	y := New()
+	for _, tt := range tests {
+		got, err := applyRule(y, tt.rule, tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+	for _, rule := range []string{"percent:-1", "percent:x", "percent:0:x"} {
+		if _, err := y.ParseStringRule(rule); err == nil {
+			t.Errorf("%s: expected error", rule)
+		}
+	}
+}
+
+func TestOrdinal(t *testing.T) {
+	tests := []struct {
+		in, want string
+		ok       bool
+	}{
+		{"1", "1st", true},
+		{"2", "2nd", true},
+		{"3", "3rd", true},
+		{"4", "4th", true},
+		{"11", "11th", true},
+		{"12", "12th", true},
+		{"13", "13th", true},
+		{"21", "21st", true},
+		{"112", "112th", true},
+		{"1002", "1002nd", true},
+		{" 0 ", "0th", true},
+		{"-1", "-1st", true},
+		{"1.5", "", false},
+		{"x", "", false},
+	}
+	y := New()
+	for _, tt := range tests {
+		got, err := applyRule(y, "ordinal", tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("ordinal(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestFixedDec(t *testing.T) {
+	tests := []struct {
+		rule, in, want string
+		ok             bool
+	}{
+		{"fixeddec:2", "3", "3.00", true},
+		{"fixeddec:2", " 2.675 ", "2.67", true},
+		{"fixeddec:0", "2.5", "2", true},
+		{"fixeddec:1", "-0.04", "-0.0", true},
+		{"fixeddec:3", "1e3", "1000.000", true},
+		{"fixeddec:2", "x", "", false},
+	}
+	y := New()
+	for _, tt := range tests {
+		got, err := applyRule(y, tt.rule, tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+	for _, rule := range []string{"fixeddec", "fixeddec:-1", "fixeddec:x"} {
+		if _, err := y.ParseStringRule(rule); err == nil {
+			t.Errorf("%s: expected error", rule)
+		}
+	}
+}
+
+func TestRange(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"1-5", "1,2,3,4,5"},
+		{"1-10:3", "1,4,7,10"},
+		{"1-9:3", "1,4,7"},
+		{"5-1:-2", "5,3,1"},
+		{"-3--1", "-3,-2,-1"},
+		{"a-e:2", "a,c,e"},
+		{"4-4", "4"},
+		{"9223372036854775806-9223372036854775807", "9223372036854775806,9223372036854775807"},
+		{"-9223372036854775807--9223372036854775808:-1", "-9223372036854775807,-9223372036854775808"},
+		{"-9223372036854775808-9223372036854775807:9223372036854775807", "-9223372036854775808,-1,9223372036854775806"},
+	}
+	f := New().Range(",")
+	for _, tt := range tests {
+		if got, err := f(tt.in); err != nil || got != tt.want {
+			t.Errorf("Range(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestPlural(t *testing.T) {
+	tests := []struct {
+		in, want string
+		ok       bool
+	}{
+		{"1", "1 item", true},
+		{" -1 ", "-1 item", true},
+		{"+1", "+1 item", true},
+		{"0", "0 items", true},
+		{"2", "2 items", true},
+		{"1.0", "1.0 items", true},
+		{"1.5", "1.5 items", true},
+		{"NaN", "", false},
+		{"Inf", "", false},
+		{"-inf", "", false},
+		{"0x1p0", "", false},
+		{"1e400", "", false},
+		{"one", "", false},
+	}
+	y := New()
+	for _, tt := range tests {
+		got, err := applyRule(y, "plural:{n} item:{n} items", tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("plural(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := y.ParseStringRule("plural:{n} item"); err == nil {
+		t.Error("expected error for missing template")
+	}
+}
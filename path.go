@@ -0,0 +1,121 @@
+package transform
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DedupExt collapses a repeated trailing file extension, e.g.
+// "report.pdf.pdf" becomes "report.pdf". Only identical extensions (compared
+// case-insensitively) are collapsed, so "archive.tar.gz" is left alone. The
+// first occurrence of the extension is kept.
+func (*Transform) DedupExt(s string) (string, error) {
+	for {
+		ext := filepath.Ext(s)
+		if ext == "" || ext == "." {
+			return s, nil
+		}
+		base := s[:len(s)-len(ext)]
+		if !strings.EqualFold(filepath.Ext(base), ext) {
+			return s, nil
+		}
+		s = base
+	}
+}
+
+// FilePerm converts an octal file mode like "0644" or "4755" into its
+// symbolic form like "-rw-r--r--" or "-rwsr-xr-x", as shown by ls -l.
+// Setuid, setgid and sticky bits are supported. As octal modes carry no file
+// type, the type character is always "-" (regular file).
+func (*Transform) FilePerm(s string) (string, error) {
+	m, err := strconv.ParseUint(strings.TrimSpace(s), 8, 32)
+	if err != nil || m > 07777 {
+		return "", errors.New("fileperm: invalid octal mode: " + s)
+	}
+
+	b := []byte("-rwxrwxrwx")
+	for i := 0; i < 9; i++ {
+		if m&(1<<uint(8-i)) == 0 {
+			b[i+1] = '-'
+		}
+	}
+	special := func(bit uint64, i int, c byte) {
+		if m&bit != 0 {
+			if b[i] == '-' {
+				c -= 'a' - 'A'
+			}
+			b[i] = c
+		}
+	}
+	special(04000, 3, 's')
+	special(02000, 6, 's')
+	special(01000, 9, 't')
+	return string(b), nil
+}
+
+// FilePermOctal converts a symbolic file mode like "-rw-r--r--" or
+// "drwxr-xr-x" into its four-digit octal form like "0644". The leading file
+// type character is ignored, and may be omitted.
+func (*Transform) FilePermOctal(s string) (string, error) {
+	p := strings.TrimSpace(s)
+	if len(p) == 10 {
+		p = p[1:]
+	}
+	if len(p) != 9 {
+		return "", errors.New("fileperm: invalid symbolic mode: " + s)
+	}
+
+	var m uint64
+	for i := 0; i < 9; i++ {
+		c := p[i]
+		switch {
+		case c == "rwxrwxrwx"[i]:
+			m |= 1 << uint(8-i)
+		case c == '-':
+		case i%3 == 2 && (c == 's' || c == 'S') && i < 8 || i == 8 && (c == 't' || c == 'T'):
+			m |= 01000 << uint(2-i/3)
+			if c == 's' || c == 't' {
+				m |= 1 << uint(8-i)
+			}
+		default:
+			return "", errors.New("fileperm: invalid symbolic mode: " + s)
+		}
+	}
+	return fmt.Sprintf("%04o", m), nil
+}
+
+// JoinPath joins directories separated by newlines or commas into a path
+// list like the PATH environment variable, using the operating system's path
+// list separator (":" on Unix, ";" on Windows). Each entry is cleaned, and
+// empty and duplicate entries are dropped while preserving the order.
+func (*Transform) JoinPath(s string) (string, error) {
+	var dirs []string
+	seen := map[string]bool{}
+	for _, d := range strings.FieldsFunc(s, func(r rune) bool { return r == '\n' || r == '\r' || r == ',' }) {
+		if d = strings.TrimSpace(d); d == "" {
+			continue
+		}
+		if d = filepath.Clean(d); !seen[d] {
+			seen[d] = true
+			dirs = append(dirs, d)
+		}
+	}
+	return strings.Join(dirs, string(filepath.ListSeparator)), nil
+}
+
+// SplitPath splits a path list like the PATH environment variable at the
+// operating system's path list separator and returns the entries separated
+// by newlines. Empty entries are dropped.
+func (*Transform) SplitPath(s string) (string, error) {
+	var dirs []string
+	for _, d := range filepath.SplitList(s) {
+		if d != "" {
+			dirs = append(dirs, d)
+		}
+	}
+	return strings.Join(dirs, "\n"), nil
+}
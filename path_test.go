@@ -0,0 +1,76 @@
+package transform
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDedupExt(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"report.pdf.pdf", "report.pdf"},
+		{"report.PDF.pdf.Pdf", "report.PDF"},
+		{"archive.tar.gz", "archive.tar.gz"},
+		{"dir.x/file.x", "dir.x/file.x"},
+		{"noext", "noext"},
+		{"file.", "file."},
+	}
+	y := New()
+	for _, tt := range tests {
+		if got, err := applyRule(y, "dedupext", tt.in); err != nil || got != tt.want {
+			t.Errorf("dedupext(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestFilePerm(t *testing.T) {
+	tests := []struct {
+		rule, in, want string
+		ok             bool
+	}{
+		{"fileperm", "0644", "-rw-r--r--", true},
+		{"fileperm", "755", "-rwxr-xr-x", true},
+		{"fileperm:symbolic", "4755", "-rwsr-xr-x", true},
+		{"fileperm", "2644", "-rw-r-Sr--", true},
+		{"fileperm", "1777", "-rwxrwxrwt", true},
+		{"fileperm", "0888", "", false},
+		{"fileperm", "17777", "", false},
+		{"fileperm:octal", "-rw-r--r--", "0644", true},
+		{"fileperm:octal", "drwxr-xr-x", "0755", true},
+		{"fileperm:octal", "rwsr-sr-T", "7754", true},
+		{"fileperm:octal", "rwSr-s---", "6650", true},
+		{"fileperm:octal", "rwxrwxrwt", "1777", true},
+		{"fileperm:octal", "-rw-r--r", "", false},
+		{"fileperm:octal", "-rw-r--r-s", "", false},
+	}
+	y := New()
+	for _, tt := range tests {
+		got, err := applyRule(y, tt.rule, tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := y.ParseStringRule("fileperm:x"); err == nil {
+		t.Error("expected error for invalid mode")
+	}
+}
+
+func TestJoinPath(t *testing.T) {
+	sep := string(filepath.ListSeparator)
+	tests := []struct {
+		rule, in, want string
+	}{
+		{"joinpath", "/usr/bin\n /bin/ \r\n\n/usr/bin,/usr/local/bin/../sbin", "/usr/bin" + sep + "/bin" + sep + "/usr/local/sbin"},
+		{"splitpath", strings.Join([]string{"/a", "", "/b"}, sep), "/a\n/b"},
+		{"joinpath", "", ""},
+		{"splitpath", "", ""},
+	}
+	y := New()
+	for _, tt := range tests {
+		if got, err := applyRule(y, tt.rule, tt.in); err != nil || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+}
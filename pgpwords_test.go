@@ -0,0 +1,33 @@
+package transform
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFingerWords(t *testing.T) {
+	tests := []struct {
+		rule, in, want string
+	}{
+		{"fingerwords:raw", "\x00\x00\xff\xff", "aardvark adroitness Zulu Yucatan"},
+		{"fingerwords:raw", "\xe5\x82", "topmost Istanbul"},
+		{"fingerwords:raw", "", ""},
+		{"fingerwords:2", "hello", "Burbank vagabond"},
+	}
+	y := New()
+	for _, tt := range tests {
+		if got, err := applyRule(y, tt.rule, tt.in); err != nil || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+	for rule, n := range map[string]int{"fingerwords": 8, "fingerwords:32": 32} {
+		if got, _ := applyRule(y, rule, "hello"); len(strings.Fields(got)) != n {
+			t.Errorf("%s: got %q, want %d words", rule, got, n)
+		}
+	}
+	for _, rule := range []string{"fingerwords:0", "fingerwords:33", "fingerwords:x"} {
+		if _, err := y.ParseStringRule(rule); err == nil {
+			t.Errorf("%s: expected error", rule)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package transform
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// maxTokenBytes is the largest number of random bytes in a token.
+const maxTokenBytes = 1024
+
+// tokenEncodings indexes the supported random token encodings by name.
+var tokenEncodings = map[string]func([]byte) string{
+	"hex":       hex.EncodeToString,
+	"base64url": base64.RawURLEncoding.EncodeToString,
+	"base62":    base62,
+}
+
+// base62 encodes bytes as a big-endian number in base 62 (0-9, a-z, A-Z),
+// padded with zeros to the length required for the largest number of that
+// many bytes.
+func base62(b []byte) string {
+	max := new(big.Int).Lsh(big.NewInt(1), uint(8*len(b)))
+	n := len(max.Sub(max, big.NewInt(1)).Text(62))
+	s := new(big.Int).SetBytes(b).Text(62)
+	return strings.Repeat("0", n-len(s)) + s
+}
+
+// RandSource returns an option func that sets the source of random bytes used
+// by handlers like "randtoken", e.g. for deterministic tests. It defaults to
+// crypto/rand.Reader. The reader is shared by all uses of the Transform and
+// its compiled snapshots, so it must be safe for concurrent use if they are
+// used concurrently.
+func RandSource(r io.Reader) TransformOption {
+	return func(t *Transform) {
+		t.RandSource = r
+	}
+}
+
+// RandToken returns a function that ignores its input and returns a token of
+// n random bytes, encoded using the named encoding: "hex", "base64url"
+// (unpadded) or "base62". n must be between 1 and 1024.
+func (t *Transform) RandToken(n int, encoding string) (TransformFunc, error) {
+	if n < 1 || n > maxTokenBytes {
+		return nil, errors.Errorf("randtoken: invalid length: %d", n)
+	}
+	enc := tokenEncodings[encoding]
+	if enc == nil {
+		return nil, errors.New("randtoken: unknown encoding: " + encoding)
+	}
+	return func(string) (string, error) {
+		r := t.RandSource
+		if r == nil {
+			r = rand.Reader
+		}
+		b := make([]byte, n)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return "", errors.Wrap(err, "randtoken")
+		}
+		return enc(b), nil
+	}, nil
+}
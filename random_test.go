@@ -0,0 +1,38 @@
+package transform
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRandToken(t *testing.T) {
+	tests := []struct {
+		rule, want string
+	}{
+		{"randtoken:4", "00010203"},
+		{"randtoken:3:base64url", "AAEC"},
+		{"randtoken:2:base62", "001"},
+		{"randtoken:1024", strings.Repeat("00", 1024)},
+	}
+	for _, tt := range tests {
+		src := []byte{0, 1, 2, 3}
+		if strings.HasSuffix(tt.rule, "1024") {
+			src = make([]byte, 1024)
+		}
+		y := New(RandSource(bytes.NewReader(src)))
+		if got, err := applyRule(y, tt.rule, "ignored"); err != nil || got != tt.want {
+			t.Errorf("%s = %q, %v, want %q", tt.rule, got, err, tt.want)
+		}
+	}
+
+	y := New(RandSource(bytes.NewReader(nil)))
+	for _, rule := range []string{"randtoken:0", "randtoken:1025", "randtoken:x", "randtoken:4:base32"} {
+		if _, err := y.ParseStringRule(rule); err == nil {
+			t.Errorf("%s: expected error", rule)
+		}
+	}
+	if _, err := applyRule(y, "randtoken:4", ""); err == nil {
+		t.Error("expected error on short random source")
+	}
+}
@@ -0,0 +1,34 @@
+package transform
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// TransformFields splits a delimited record like a CSV line into fields,
+// applies the rules configured for each field index (starting at 0) and
+// joins the fields using the same delimiter. Fields without rules are kept
+// unchanged, and rules for indices beyond the number of fields are ignored.
+// Quoting is not supported. Field rules are not recorded by the Metrics
+// option, as their indices do not refer to Transform.Rules.
+func (t *Transform) TransformFields(line string, delim string, fieldRules map[int][]TransformFunc) (string, error) {
+	if delim == "" {
+		return "", errors.New("empty field delimiter")
+	}
+	c := *t
+	c.metrics = nil
+	fields := strings.Split(line, delim)
+	for i, f := range fields {
+		ff := fieldRules[i]
+		if len(ff) == 0 {
+			continue
+		}
+		s, err := c.apply(f, ff)
+		if err != nil {
+			return "", errors.Wrapf(err, "field %d", i)
+		}
+		fields[i] = s
+	}
+	return strings.Join(fields, delim), nil
+}
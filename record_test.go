@@ -0,0 +1,46 @@
+package transform
+
+import (
+	"testing"
+)
+
+func TestTransformFields(t *testing.T) {
+	y := New()
+	rules := map[int][]TransformFunc{
+		0: {y.Trim, y.Upcase},
+		2: {y.Trim},
+		5: {y.Upcase},
+	}
+	tests := []struct {
+		line, want string
+	}{
+		{" a ,b, c ", "A,b,c"},
+		{"x", "X"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got, err := y.TransformFields(tt.line, ",", rules); err != nil || got != tt.want {
+			t.Errorf("TransformFields(%q) = %q, %v, want %q", tt.line, got, err, tt.want)
+		}
+	}
+	if _, err := y.TransformFields("a", "", rules); err == nil {
+		t.Error("expected error for empty delimiter")
+	}
+}
+
+func TestTransformFieldsMetrics(t *testing.T) {
+	y := New(Metrics())
+	if err := y.AddStringRules("trim"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := y.Transform(" a "); err != nil {
+		t.Fatal(err)
+	}
+	rules := map[int][]TransformFunc{0: {y.Trim, y.Upcase}, 1: {y.Upcase}}
+	if _, err := y.TransformFields("a,b", ",", rules); err != nil {
+		t.Fatal(err)
+	}
+	if m := y.RuleMetrics(); len(m) != 1 || m[0].Calls != 1 {
+		t.Errorf("got metrics %+v, want one rule called once", m)
+	}
+}
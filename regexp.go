@@ -0,0 +1,27 @@
+package transform
+
+import (
+	"regexp"
+	"strings"
+)
+
+// RegexQuote returns the string with all regular expression metacharacters
+// escaped, so that it matches the literal text.
+func (*Transform) RegexQuote(s string) (string, error) {
+	return regexp.QuoteMeta(s), nil
+}
+
+// RegexClassQuote escapes the string for literal use inside a regular
+// expression character class, e.g. "[...]". The characters \ ] [ ^ and - are
+// prefixed with a backslash; all other characters are left unchanged as they
+// have no special meaning inside a class.
+func (*Transform) RegexClassQuote(s string) (string, error) {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`\][^-`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String(), nil
+}
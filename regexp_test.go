@@ -0,0 +1,42 @@
+package transform
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestRegexQuote(t *testing.T) {
+	y := New()
+	for _, in := range []string{"a.b*c", "[x](y){1,2}", `\d+$^|?`, "plain"} {
+		got, err := applyRule(y, "regexquote", in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !regexp.MustCompile("^" + got + "$").MatchString(in) {
+			t.Errorf("regexquote(%q) = %q does not match the input", in, got)
+		}
+	}
+	if got, _ := applyRule(y, "regexquote", "a.b"); got != `a\.b` {
+		t.Errorf("got %q, want %q", got, `a\.b`)
+	}
+}
+
+func TestRegexClassQuote(t *testing.T) {
+	y := New()
+	for _, in := range []string{`a-z`, `]^[\`, "._*+?", "日本"} {
+		got, err := applyRule(y, "regexclassquote", in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		re := regexp.MustCompile("^[" + got + "]+$")
+		if !re.MatchString(in) {
+			t.Errorf("regexclassquote(%q) = %q does not match the input", in, got)
+		}
+		if in == "a-z" && re.MatchString("b") {
+			t.Errorf("regexclassquote(%q) = %q matches a range", in, got)
+		}
+	}
+	if got, _ := applyRule(y, "regexclassquote", "a-]"); got != `a\-\]` {
+		t.Errorf("got %q, want %q", got, `a\-\]`)
+	}
+}
@@ -0,0 +1,808 @@
+package transform
+
+import (
+	"crypto/sha256"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/encoding"
+)
+
+// expandRule parses the arguments of an "expand" rule.
+func (t *Transform) expandRule(arg string) (TransformFunc, error) {
+	if arg == "" {
+		return nil, errors.New("expand: missing regex")
+	}
+	// A trailing ":default=<value>" sets the value used for keys
+	// that cannot be resolved.
+	expr, def, hasDef := arg, "", false
+	if i := strings.LastIndex(expr, ":default="); i != -1 {
+		expr, def, hasDef = expr[:i], expr[i+len(":default="):], true
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, errors.Wrap(err, "regexp: "+expr)
+	}
+	if hasDef {
+		return t.ExpandDefault(re, def)
+	}
+	f, err := t.Expand(re)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// mustacheRule parses the arguments of a "mustache" rule.
+// Simple variable substitution only, sections and other
+// mustache features are not supported.
+func (t *Transform) mustacheRule(arg string) (TransformFunc, error) {
+	if arg != "" {
+		return nil, errors.New("mustache: unexpected argument")
+	}
+	return t.Expand(regexp.MustCompile(MustacheVar))
+}
+
+// mapRule parses the arguments of a "map" rule.
+func (t *Transform) mapRule(arg string) (TransformFunc, error) {
+	args, err := ruleArgs("map", arg, 1, 2)
+	if err != nil {
+		return nil, err
+	}
+	keep := false
+	if len(args) == 2 {
+		if args[1] != "keep" {
+			return nil, errors.New("map: invalid mode: " + args[1])
+		}
+		keep = true
+	}
+	return t.Map(args[0], keep)
+}
+
+// kvgetRule parses the arguments of a "kvget" rule.
+func (t *Transform) kvgetRule(arg string) (TransformFunc, error) {
+	args, err := ruleArgs("kvget", arg, 1, 3)
+	if err != nil {
+		return nil, err
+	}
+	args = append(args, "", "")
+	return t.KVGet(args[0], args[1], args[2]), nil
+}
+
+// maxlinesRule parses the arguments of a "maxlines" rule.
+func (t *Transform) maxlinesRule(arg string) (TransformFunc, error) {
+	args, err := ruleArgs("maxlines", arg, 1, 2)
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 {
+		return nil, errors.New("maxlines: invalid line count: " + args[0])
+	}
+	marker := "…"
+	if len(args) == 2 {
+		marker = args[1]
+	}
+	return t.MaxLines(n, marker), nil
+}
+
+// wrapcjkRule parses the arguments of a "wrapcjk" rule.
+func (t *Transform) wrapcjkRule(arg string) (TransformFunc, error) {
+	args, err := ruleArgs("wrapcjk", arg, 1, 1)
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 {
+		return nil, errors.New("wrapcjk: invalid width: " + args[0])
+	}
+	return t.WrapCJK(n), nil
+}
+
+// tristateRule parses the arguments of a "tristate" rule.
+func (t *Transform) tristateRule(arg string) (TransformFunc, error) {
+	args, err := ruleArgs("tristate", arg, 0, 3)
+	if err != nil {
+		return nil, err
+	}
+	tokens := []string{"true", "false", ""}
+	copy(tokens, args)
+	return t.TriState(tokens[0], tokens[1], tokens[2]), nil
+}
+
+// semverRule parses the arguments of a "semver" rule.
+func (t *Transform) semverRule(arg string) (TransformFunc, error) {
+	args, err := ruleArgs("semver", arg, 0, 1)
+	if err != nil {
+		return nil, err
+	}
+	prefix := false
+	if len(args) == 1 {
+		switch args[0] {
+		case "v":
+			prefix = true
+		case "strip":
+		default:
+			return nil, errors.New("semver: invalid mode: " + args[0])
+		}
+	}
+	return t.Semver(prefix), nil
+}
+
+// eolRule parses the arguments of an "eol" rule.
+func (t *Transform) eolRule(arg string) (TransformFunc, error) {
+	args, err := ruleArgs("eol", arg, 1, 1)
+	if err != nil {
+		return nil, err
+	}
+	eol, ok := lineEndings[strings.ToLower(args[0])]
+	if !ok {
+		return nil, errors.New("eol: unknown line ending: " + args[0])
+	}
+	return t.EOL(eol), nil
+}
+
+// htmlunescapeRule parses the arguments of a "htmlunescape" rule.
+func (t *Transform) htmlunescapeRule(arg string) (TransformFunc, error) {
+	args, err := ruleArgs("htmlunescape", arg, 0, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		return t.HTMLUnescape, nil
+	}
+	if args[0] != "deep" {
+		return nil, errors.New("htmlunescape: invalid mode: " + args[0])
+	}
+	return t.HTMLUnescapeDeep, nil
+}
+
+// coalesceRule parses the arguments of a "coalesce" rule.
+func (t *Transform) coalesceRule(arg string) (TransformFunc, error) {
+	args, err := ruleArgs("coalesce", arg, 1, -1)
+	if err != nil {
+		return nil, err
+	}
+	return t.Coalesce(args...)
+}
+
+// seedArg parses the seed argument of the shuffle and unshuffle rules.
+func seedArg(tag, arg string) (int64, error) {
+	args, err := ruleArgs(tag, arg, 1, 1)
+	if err != nil {
+		return 0, err
+	}
+	seed, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return 0, errors.New(tag + ": invalid seed: " + args[0])
+	}
+	return seed, nil
+}
+
+// shuffleRule parses the arguments of a "shuffle" rule.
+func (t *Transform) shuffleRule(arg string) (TransformFunc, error) {
+	seed, err := seedArg("shuffle", arg)
+	if err != nil {
+		return nil, err
+	}
+	return t.Shuffle(seed), nil
+}
+
+// unshuffleRule parses the arguments of an "unshuffle" rule.
+func (t *Transform) unshuffleRule(arg string) (TransformFunc, error) {
+	seed, err := seedArg("unshuffle", arg)
+	if err != nil {
+		return nil, err
+	}
+	return t.Unshuffle(seed), nil
+}
+
+// resepRule parses the arguments of a "resep" rule.
+// resep:<input separators>:<output separator>; both arguments
+// support escape sequences (see unescapeArg), which is needed
+// for spaces, commas and colons.
+func (t *Transform) resepRule(arg string) (TransformFunc, error) {
+	args, err := ruleArgs("resep", arg, 2, 2)
+	if err != nil {
+		return nil, err
+	}
+	for i := range args {
+		if args[i], err = unescapeArg(args[i]); err != nil {
+			return nil, errors.Wrap(err, "resep")
+		}
+	}
+	if args[0] == "" {
+		return nil, errors.New("resep: missing input separators")
+	}
+	return t.Resep(args[0], args[1]), nil
+}
+
+// jsonrequireRule parses the arguments of a "jsonrequire" rule.
+// Keys may be separated by commas or, for use with
+// AddStringRules, colons.
+func (t *Transform) jsonrequireRule(arg string) (TransformFunc, error) {
+	if arg == "" {
+		return nil, errors.New("jsonrequire: missing keys")
+	}
+	var keys []string
+	for _, k := range strings.FieldsFunc(arg, func(r rune) bool { return r == ',' || r == ':' }) {
+		if k = strings.TrimSpace(k); k != "" {
+			keys = append(keys, k)
+		}
+	}
+	return t.JSONRequire(keys...), nil
+}
+
+// jsongetRule parses the arguments of a "jsonget" rule.
+func (t *Transform) jsongetRule(arg string) (TransformFunc, error) {
+	args, err := ruleArgs("jsonget", arg, 1, 2)
+	if err != nil {
+		return nil, err
+	}
+	allowMissing := false
+	if len(args) == 2 {
+		switch args[1] {
+		case "empty":
+			allowMissing = true
+		case "error":
+		default:
+			return nil, errors.New("jsonget: invalid mode: " + args[1])
+		}
+	}
+	return t.JSONGet(args[0], allowMissing), nil
+}
+
+// tzRule parses the arguments of a "tz" rule.
+func (t *Transform) tzRule(arg string) (TransformFunc, error) {
+	args, err := ruleArgs("tz", arg, 1, 1)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := time.LoadLocation(args[0])
+	if err != nil {
+		return nil, errors.Wrap(err, "tz")
+	}
+	return t.TZ(loc), nil
+}
+
+// exportRule parses the arguments of an "export" rule.
+func (t *Transform) exportRule(arg string) (TransformFunc, error) {
+	args, err := ruleArgs("export", arg, 1, 1)
+	if err != nil {
+		return nil, err
+	}
+	return t.Export(args[0])
+}
+
+// bucketRule parses the arguments of a "bucket" rule.
+func (t *Transform) bucketRule(arg string) (TransformFunc, error) {
+	args, err := ruleArgs("bucket", arg, 1, 1)
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil || n == 0 {
+		return nil, errors.New("bucket: invalid bucket count: " + args[0])
+	}
+	return t.Bucket(n), nil
+}
+
+// percentRule parses the arguments of a "percent" rule.
+func (t *Transform) percentRule(arg string) (TransformFunc, error) {
+	args, err := ruleArgs("percent", arg, 0, 2)
+	if err != nil {
+		return nil, err
+	}
+	decimals := 0
+	if len(args) > 0 && args[0] != "" {
+		if decimals, err = strconv.Atoi(args[0]); err != nil || decimals < 0 {
+			return nil, errors.New("percent: invalid number of decimals: " + args[0])
+		}
+	}
+	sign := true
+	if len(args) > 1 {
+		if args[1] != "nosign" {
+			return nil, errors.New("percent: invalid mode: " + args[1])
+		}
+		sign = false
+	}
+	return t.Percent(decimals, sign), nil
+}
+
+// charsetArg parses the charset argument of the toutf8, decode and encode
+// rules.
+func charsetArg(tag, arg string) (encoding.Encoding, error) {
+	args, err := ruleArgs(tag, arg, 1, 1)
+	if err != nil {
+		return nil, err
+	}
+	enc, err := charset(args[0])
+	if err != nil {
+		return nil, errors.Wrap(err, tag)
+	}
+	return enc, nil
+}
+
+// toutf8Rule parses the arguments of a "toutf8" rule.
+func (t *Transform) toutf8Rule(arg string) (TransformFunc, error) {
+	enc, err := charsetArg("toutf8", arg)
+	if err != nil {
+		return nil, err
+	}
+	return t.ToUTF8(enc), nil
+}
+
+// decodeRule parses the arguments of a "decode" rule.
+func (t *Transform) decodeRule(arg string) (TransformFunc, error) {
+	enc, err := charsetArg("decode", arg)
+	if err != nil {
+		return nil, err
+	}
+	return t.ToUTF8(enc), nil
+}
+
+// encodeRule parses the arguments of an "encode" rule.
+func (t *Transform) encodeRule(arg string) (TransformFunc, error) {
+	enc, err := charsetArg("encode", arg)
+	if err != nil {
+		return nil, err
+	}
+	return t.FromUTF8(enc), nil
+}
+
+// initialsRule parses the arguments of an "initials" rule.
+func (t *Transform) initialsRule(arg string) (TransformFunc, error) {
+	args, err := ruleArgs("initials", arg, 0, 1)
+	if err != nil {
+		return nil, err
+	}
+	max := 0
+	if len(args) == 1 {
+		if max, err = strconv.Atoi(args[0]); err != nil || max < 1 {
+			return nil, errors.New("initials: invalid limit: " + args[0])
+		}
+	}
+	return t.Initials(max), nil
+}
+
+// minlenRule parses the arguments of a "minlen" rule.
+// minlen:<n>[:error] or minlen:<n>:pad[:<padding>]
+func (t *Transform) minlenRule(arg string) (TransformFunc, error) {
+	args, err := ruleArgs("minlen", arg, 1, 3)
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 0 {
+		return nil, errors.New("minlen: invalid length: " + args[0])
+	}
+	pad := ""
+	if len(args) > 1 {
+		switch args[1] {
+		case "error":
+			if len(args) > 2 {
+				return nil, errors.New("minlen: unexpected argument: " + args[2])
+			}
+		case "pad":
+			pad = " "
+			if len(args) > 2 && args[2] != "" {
+				pad = args[2]
+			}
+		default:
+			return nil, errors.New("minlen: invalid mode: " + args[1])
+		}
+	}
+	return t.MinLen(n, pad), nil
+}
+
+// jqRule parses the arguments of a "jq" rule.
+func (t *Transform) jqRule(arg string) (TransformFunc, error) {
+	if arg == "" {
+		return nil, errors.New("jq: missing expression")
+	}
+	return t.JQ(arg)
+}
+
+// fingerwordsRule parses the arguments of a "fingerwords" rule.
+// fingerwords[:<n>|raw] encodes the first n bytes (default 8)
+// of the SHA-256 hash of the input, or the raw input.
+func (t *Transform) fingerwordsRule(arg string) (TransformFunc, error) {
+	args, err := ruleArgs("fingerwords", arg, 0, 1)
+	if err != nil {
+		return nil, err
+	}
+	n := 8
+	if len(args) == 1 {
+		if args[0] == "raw" {
+			n = 0
+		} else if n, err = strconv.Atoi(args[0]); err != nil || n < 1 || n > sha256.Size {
+			return nil, errors.New("fingerwords: invalid length: " + args[0])
+		}
+	}
+	return t.FingerWords(n), nil
+}
+
+// tableRule parses the arguments of a "table" rule.
+// table:align[:<separator>[:<alignments>]], where the
+// separator supports escape sequences (see unescapeArg) and
+// the alignments are given as a string of 'l' and 'r'.
+func (t *Transform) tableRule(arg string) (TransformFunc, error) {
+	args, err := ruleArgs("table", arg, 1, 3)
+	if err != nil {
+		return nil, err
+	}
+	if args[0] != "align" {
+		return nil, errors.New("table: invalid mode: " + args[0])
+	}
+	args = append(args, "", "")
+	sep, err := unescapeArg(args[1])
+	if err != nil {
+		return nil, errors.Wrap(err, "table")
+	}
+	if strings.Trim(args[2], "lr") != "" {
+		return nil, errors.New("table: invalid alignments: " + args[2])
+	}
+	return t.AlignTable(sep, args[2]), nil
+}
+
+// normpunctRule parses the arguments of a "normpunct" rule.
+func (t *Transform) normpunctRule(arg string) (TransformFunc, error) {
+	args, err := ruleArgs("normpunct", arg, 0, 1)
+	if err != nil {
+		return nil, err
+	}
+	ellipsis := true
+	if len(args) == 1 {
+		switch args[0] {
+		case "noellipsis":
+			ellipsis = false
+		case "ellipsis":
+		default:
+			return nil, errors.New("normpunct: invalid mode: " + args[0])
+		}
+	}
+	return t.NormPunct(ellipsis), nil
+}
+
+// acronymRule parses the arguments of an "acronym" rule.
+func (t *Transform) acronymRule(arg string) (TransformFunc, error) {
+	args, err := ruleArgs("acronym", arg, 0, 2)
+	if err != nil {
+		return nil, err
+	}
+	skipSmall, hyphens := false, true
+	for _, arg := range args {
+		switch arg {
+		case "skipsmall":
+			skipSmall = true
+		case "nohyphens":
+			hyphens = false
+		default:
+			return nil, errors.New("acronym: invalid option: " + arg)
+		}
+	}
+	return t.Acronym(skipSmall, hyphens), nil
+}
+
+// randtokenRule parses the arguments of a "randtoken" rule.
+func (t *Transform) randtokenRule(arg string) (TransformFunc, error) {
+	args, err := ruleArgs("randtoken", arg, 1, 2)
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 || n > maxTokenBytes {
+		return nil, errors.New("randtoken: invalid length: " + args[0])
+	}
+	enc := "hex"
+	if len(args) == 2 {
+		enc = args[1]
+	}
+	return t.RandToken(n, enc)
+}
+
+// pathnormRule parses the arguments of a "pathnorm" rule.
+func (t *Transform) pathnormRule(arg string) (TransformFunc, error) {
+	args, err := ruleArgs("pathnorm", arg, 1, 1)
+	if err != nil {
+		return nil, err
+	}
+	switch args[0] {
+	case "dotted":
+		return t.PathNorm(false), nil
+	case "pointer":
+		return t.PathNorm(true), nil
+	}
+	return nil, errors.New("pathnorm: invalid target form: " + args[0])
+}
+
+// fixeddecRule parses the arguments of a "fixeddec" rule.
+func (t *Transform) fixeddecRule(arg string) (TransformFunc, error) {
+	args, err := ruleArgs("fixeddec", arg, 1, 1)
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 0 {
+		return nil, errors.New("fixeddec: invalid number of decimals: " + args[0])
+	}
+	return t.FixedDec(n), nil
+}
+
+// onelineRule parses the arguments of a "oneline" rule.
+// oneline[:marker] joins lines with a space, or with a
+// literal \n if the marker mode is given.
+func (t *Transform) onelineRule(arg string) (TransformFunc, error) {
+	args, err := ruleArgs("oneline", arg, 0, 1)
+	if err != nil {
+		return nil, err
+	}
+	sep := " "
+	if len(args) == 1 {
+		if args[0] != "marker" {
+			return nil, errors.New("oneline: invalid mode: " + args[0])
+		}
+		sep = `\n`
+	}
+	return t.OneLine(sep), nil
+}
+
+// urlpathRule parses the arguments of a "urlpath" rule.
+func (t *Transform) urlpathRule(arg string) (TransformFunc, error) {
+	args, err := ruleArgs("urlpath", arg, 0, 1)
+	if err != nil {
+		return nil, err
+	}
+	pathOnly := false
+	if len(args) == 1 {
+		if args[0] != "pathonly" {
+			return nil, errors.New("urlpath: invalid mode: " + args[0])
+		}
+		pathOnly = true
+	}
+	return t.URLPath(pathOnly), nil
+}
+
+// setRule parses the arguments of a "set" rule.
+// set[:<separator>[:keepcase]], where the separator defaults
+// to a comma and supports escape sequences (see unescapeArg).
+func (t *Transform) setRule(arg string) (TransformFunc, error) {
+	args, err := ruleArgs("set", arg, 0, 2)
+	if err != nil {
+		return nil, err
+	}
+	sep := ","
+	if len(args) > 0 && args[0] != "" {
+		if sep, err = unescapeArg(args[0]); err != nil {
+			return nil, errors.Wrap(err, "set")
+		}
+	}
+	fold := true
+	if len(args) > 1 {
+		if args[1] != "keepcase" {
+			return nil, errors.New("set: invalid mode: " + args[1])
+		}
+		fold = false
+	}
+	return t.Set(sep, fold), nil
+}
+
+// dateorRule parses the arguments of a "dateor" rule.
+// dateor:<layout>|<default>; the arguments are separated by a
+// pipe as layouts commonly contain colons.
+func (t *Transform) dateorRule(arg string) (TransformFunc, error) {
+	if arg == "" {
+		return nil, errors.New("dateor: missing layout")
+	}
+	layout, def, ok := strings.Cut(arg, "|")
+	if !ok || layout == "" {
+		return nil, errors.New("dateor: expected <layout>|<default>: " + arg)
+	}
+	return t.DateOr(layout, def), nil
+}
+
+// boolsymbolRule parses the arguments of a "boolsymbol" rule.
+func (t *Transform) boolsymbolRule(arg string) (TransformFunc, error) {
+	args, err := ruleArgs("boolsymbol", arg, 0, 3)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 1 {
+		return nil, errors.New("boolsymbol: expected symbols for both true and false")
+	}
+	symbols := []string{"✓", "✗", ""}
+	copy(symbols, args)
+	return t.BoolSymbol(symbols[0], symbols[1], symbols[2]), nil
+}
+
+// uniqlinesRule parses the arguments of a "uniqlines" rule.
+func (t *Transform) uniqlinesRule(arg string) (TransformFunc, error) {
+	args, err := ruleArgs("uniqlines", arg, 0, 1)
+	if err != nil {
+		return nil, err
+	}
+	count := false
+	if len(args) == 1 {
+		if args[0] != "count" {
+			return nil, errors.New("uniqlines: invalid mode: " + args[0])
+		}
+		count = true
+	}
+	return t.UniqLines(count), nil
+}
+
+// filepermRule parses the arguments of a "fileperm" rule.
+// fileperm converts octal to symbolic modes, fileperm:octal
+// does the reverse.
+func (t *Transform) filepermRule(arg string) (TransformFunc, error) {
+	args, err := ruleArgs("fileperm", arg, 0, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 || args[0] == "symbolic" {
+		return t.FilePerm, nil
+	}
+	if args[0] != "octal" {
+		return nil, errors.New("fileperm: invalid mode: " + args[0])
+	}
+	return t.FilePermOctal, nil
+}
+
+// emaillistRule parses the arguments of an "emaillist" rule.
+// emaillist[:<separator>[:<output separator>]], where the
+// separators default to "," and ", " and support escape
+// sequences (see unescapeArg).
+func (t *Transform) emaillistRule(arg string) (TransformFunc, error) {
+	args, err := ruleArgs("emaillist", arg, 0, 2)
+	if err != nil {
+		return nil, err
+	}
+	seps := []string{",", ", "}
+	for i, a := range args {
+		if a == "" {
+			continue
+		}
+		if seps[i], err = unescapeArg(a); err != nil {
+			return nil, errors.Wrap(err, "emaillist")
+		}
+	}
+	return t.EmailList(seps[0], seps[1]), nil
+}
+
+// rangeRule parses the arguments of a "range" rule.
+// range[:<separator>], where the separator defaults to a comma
+// and supports escape sequences (see unescapeArg).
+func (t *Transform) rangeRule(arg string) (TransformFunc, error) {
+	args, err := ruleArgs("range", arg, 0, 1)
+	if err != nil {
+		return nil, err
+	}
+	sep := ","
+	if len(args) == 1 && args[0] != "" {
+		if sep, err = unescapeArg(args[0]); err != nil {
+			return nil, errors.Wrap(err, "range")
+		}
+	}
+	return t.Range(sep), nil
+}
+
+// recaseRule parses the arguments of a "recase" rule.
+func (t *Transform) recaseRule(arg string) (TransformFunc, error) {
+	args, err := ruleArgs("recase", arg, 2, 2)
+	if err != nil {
+		return nil, err
+	}
+	return t.Recase(strings.ToLower(args[0]), strings.ToLower(args[1]))
+}
+
+// pluralRule parses the arguments of a "plural" rule.
+func (t *Transform) pluralRule(arg string) (TransformFunc, error) {
+	args, err := ruleArgs("plural", arg, 2, 2)
+	if err != nil {
+		return nil, err
+	}
+	return t.Plural(args[0], args[1]), nil
+}
+
+// normbulletsRule parses the arguments of a "normbullets" rule.
+func (t *Transform) normbulletsRule(arg string) (TransformFunc, error) {
+	args, err := ruleArgs("normbullets", arg, 0, 1)
+	if err != nil {
+		return nil, err
+	}
+	marker := "-"
+	if len(args) == 1 && args[0] != "" {
+		marker = args[0]
+	}
+	return t.NormBullets(marker), nil
+}
+
+// stripemojiRule parses the arguments of a "stripemoji" rule.
+func (t *Transform) stripemojiRule(arg string) (TransformFunc, error) {
+	args, err := ruleArgs("stripemoji", arg, 0, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		return t.StripEmoji, nil
+	}
+	if args[0] != "placeholder" {
+		return nil, errors.New("stripemoji: invalid mode: " + args[0])
+	}
+	return t.EmojiPlaceholder, nil
+}
+
+// portRule parses the arguments of a "port" rule.
+func (t *Transform) portRule(arg string) (TransformFunc, error) {
+	args, err := ruleArgs("port", arg, 0, 1)
+	if err != nil {
+		return nil, err
+	}
+	allowZero := false
+	if len(args) == 1 {
+		if args[0] != "allowzero" {
+			return nil, errors.New("port: invalid mode: " + args[0])
+		}
+		allowZero = true
+	}
+	return t.Port(allowZero), nil
+}
+
+// cleanurlpathRule parses the arguments of a "cleanurlpath" rule.
+func (t *Transform) cleanurlpathRule(arg string) (TransformFunc, error) {
+	args, err := ruleArgs("cleanurlpath", arg, 0, 1)
+	if err != nil {
+		return nil, err
+	}
+	leadingSlash := false
+	if len(args) == 1 {
+		if args[0] != "slash" {
+			return nil, errors.New("cleanurlpath: invalid mode: " + args[0])
+		}
+		leadingSlash = true
+	}
+	return t.CleanURLPath(leadingSlash), nil
+}
+
+// truncateRule parses the arguments of a "truncate" rule.
+// truncate:<n>[:<ellipsis>], where the ellipsis defaults to
+// "…"; an empty ellipsis cuts the input without a suffix. An
+// explicit ellipsis must be shorter than n runes.
+func (t *Transform) truncateRule(arg string) (TransformFunc, error) {
+	args, err := ruleArgs("truncate", arg, 1, 2)
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 {
+		return nil, errors.New("truncate: invalid length, expected a positive integer: " + args[0])
+	}
+	ellipsis := "…"
+	if len(args) == 2 {
+		ellipsis = args[1]
+		if utf8.RuneCountInString(ellipsis) >= n {
+			return nil, errors.New("truncate: ellipsis must be shorter than the length: " + ellipsis)
+		}
+	}
+	return t.Truncate(n, ellipsis), nil
+}
+
+// fromepochRule parses the arguments of a "fromepoch" rule.
+func (t *Transform) fromepochRule(arg string) (TransformFunc, error) {
+	args, err := ruleArgs("fromepoch", arg, 0, 1)
+	if err != nil {
+		return nil, err
+	}
+	ms := false
+	if len(args) == 1 {
+		if args[0] != "ms" {
+			return nil, errors.New("fromepoch: invalid unit: " + args[0])
+		}
+		ms = true
+	}
+	return t.FromEpoch(ms), nil
+}
@@ -0,0 +1,28 @@
+package transform
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// shellIdentRE matches a valid POSIX shell variable name.
+var shellIdentRE = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// shellQuote returns the string enclosed in single quotes, with embedded
+// single quotes escaped, for safe use in POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Export returns a function that turns its input into a shell statement
+// exporting the value as the given variable, e.g. "export KEY='value'".
+func (*Transform) Export(key string) (TransformFunc, error) {
+	if !shellIdentRE.MatchString(key) {
+		return nil, errors.New("invalid shell variable name: " + key)
+	}
+	return func(s string) (string, error) {
+		return "export " + key + "=" + shellQuote(s), nil
+	}, nil
+}
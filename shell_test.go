@@ -0,0 +1,27 @@
+package transform
+
+import (
+	"testing"
+)
+
+func TestExport(t *testing.T) {
+	tests := []struct {
+		rule, in, want string
+	}{
+		{"export:KEY", "value", "export KEY='value'"},
+		{"export:_k1", "it's", `export _k1='it'\''s'`},
+		{"export:X", "", "export X=''"},
+		{"export:X", "$HOME `x`", "export X='$HOME `x`'"},
+	}
+	y := New()
+	for _, tt := range tests {
+		if got, err := applyRule(y, tt.rule, tt.in); err != nil || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+	for _, rule := range []string{"export", "export:1KEY", "export:A-B", "export:A:B"} {
+		if _, err := y.ParseStringRule(rule); err == nil {
+			t.Errorf("%s: expected error", rule)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package transform
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// TransformStructFields applies the given string rule to all string fields of
+// the struct pointed to by v whose name matches the given predicate. Nested
+// structs and pointers to structs are traversed; unexported fields are
+// skipped. Each struct reached by pointer is processed only once, so shared
+// and cyclic pointers are handled.
+func (t *Transform) TransformStructFields(v interface{}, rule string, pred func(field string) bool) error {
+	ff, err := t.parseStringRules(rule)
+	if err != nil || len(ff) == 0 {
+		return err
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("expected non-nil pointer to struct")
+	}
+	seen := structSet{}
+	seen.add(rv)
+	return t.transformStructFields(rv.Elem(), "", ff, pred, seen)
+}
+
+// structKey identifies a struct reached by pointer. The type is part of the
+// key, as a struct and its first field share the same address.
+type structKey struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// structSet records the structs visited while traversing pointers.
+type structSet map[structKey]bool
+
+// add records the struct the pointer p points to and reports whether it had
+// not been visited yet.
+func (s structSet) add(p reflect.Value) bool {
+	k := structKey{p.Pointer(), p.Type()}
+	if s[k] {
+		return false
+	}
+	s[k] = true
+	return true
+}
+
+// transformStructFields applies the transformation functions to the matching
+// string fields of the given struct value. The path prefix identifies the
+// struct in error messages.
+func (t *Transform) transformStructFields(rv reflect.Value, prefix string, ff []TransformFunc, pred func(string) bool, seen structSet) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		fv := rv.Field(i)
+		path := prefix + sf.Name
+
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() || fv.Elem().Kind() != reflect.Struct || !seen.add(fv) {
+				continue
+			}
+			fv = fv.Elem()
+		}
+
+		switch fv.Kind() {
+		case reflect.Struct:
+			if err := t.transformStructFields(fv, path+".", ff, pred, seen); err != nil {
+				return err
+			}
+		case reflect.String:
+			if pred != nil && !pred(sf.Name) {
+				continue
+			}
+			s, err := t.Transform(fv.String(), ff...)
+			if err != nil {
+				return errors.Wrap(err, path)
+			}
+			fv.SetString(s)
+		}
+	}
+	return nil
+}
+
+// TransformStruct applies the string rules given in the "transform" tags of
+// the string fields of the struct pointed to by v, e.g.
+//
+//	Name string `transform:"trim,capitalize"`
+//
+// A leading "from=<Field>" element makes the field take its input from
+// another string field of the same struct instead of its own value, e.g.
+// `transform:"from=Title,downcase"`. Elements are separated by the configured
+// rule separator (see RuleSeparator). If the source field has rules itself,
+// it is transformed first. Cyclic dependencies result in an error. Nested
+// structs and pointers to structs are traversed; unexported fields are
+// skipped. As with TransformStructFields, each struct reached by pointer is
+// processed only once.
+func (t *Transform) TransformStruct(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("expected non-nil pointer to struct")
+	}
+	seen := structSet{}
+	seen.add(rv)
+	return t.transformStruct(rv.Elem(), "", seen)
+}
+
+// fieldRules holds the parsed transform tag of a struct field.
+type fieldRules struct {
+	from string
+	ff   []TransformFunc
+}
+
+// transformStruct applies the tagged rules to the fields of the given struct
+// value. The path prefix identifies the struct in error messages.
+func (t *Transform) transformStruct(rv reflect.Value, prefix string, seen structSet) error {
+	rt := rv.Type()
+	rules := map[string]*fieldRules{}
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		fv := rv.Field(i)
+		path := prefix + sf.Name
+
+		if fv.Kind() == reflect.Ptr && !fv.IsNil() && fv.Elem().Kind() == reflect.Struct {
+			if !seen.add(fv) {
+				continue
+			}
+			fv = fv.Elem()
+		}
+		if fv.Kind() == reflect.Struct {
+			if err := t.transformStruct(fv, path+".", seen); err != nil {
+				return err
+			}
+			continue
+		}
+
+		tag, ok := sf.Tag.Lookup("transform")
+		if !ok || fv.Kind() != reflect.String {
+			continue
+		}
+		fr := &fieldRules{}
+		elems := t.splitStringRules(tag)
+		if len(elems) > 0 && strings.HasPrefix(elems[0], "from=") {
+			fr.from = strings.TrimSpace(elems[0][len("from="):])
+			elems = elems[1:]
+		}
+		var err error
+		if fr.ff, err = t.parseRules(elems); err != nil {
+			return errors.Wrap(err, path)
+		}
+		rules[sf.Name] = fr
+	}
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := map[string]int{}
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case done:
+			return nil
+		case visiting:
+			return errors.New(prefix + name + ": cyclic field dependency")
+		}
+		state[name] = visiting
+
+		fr := rules[name]
+		fv := rv.FieldByName(name)
+		in := fv.String()
+		if fr.from != "" {
+			sf, ok := rt.FieldByName(fr.from)
+			if !ok || sf.PkgPath != "" || sf.Type.Kind() != reflect.String {
+				return errors.New(prefix + name + ": invalid source field: " + fr.from)
+			}
+			if rules[fr.from] != nil {
+				if err := visit(fr.from); err != nil {
+					return err
+				}
+			}
+			in = rv.FieldByName(fr.from).String()
+		}
+		out := in
+		if len(fr.ff) > 0 {
+			var err error
+			if out, err = t.Transform(in, fr.ff...); err != nil {
+				return errors.Wrap(err, prefix+name)
+			}
+		}
+		fv.SetString(out)
+		state[name] = done
+		return nil
+	}
+
+	for i := 0; i < rt.NumField(); i++ {
+		if name := rt.Field(i).Name; rules[name] != nil {
+			if err := visit(name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package transform
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTransformStructFields(t *testing.T) {
+	type inner struct {
+		Name string
+		Note string
+	}
+	type outer struct {
+		Name  string
+		Count int
+		In    inner
+		Ptr   *inner
+		Nil   *inner
+		Title string
+		lower string
+	}
+	v := outer{Name: " a ", In: inner{Name: " b ", Note: " c "}, Ptr: &inner{Name: " d "}, Title: " e ", lower: " f "}
+	err := New().TransformStructFields(&v, "trim", func(field string) bool {
+		return strings.HasPrefix(field, "Name") || field == "Title"
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := outer{Name: "a", In: inner{Name: "b", Note: " c "}, Ptr: &inner{Name: "d"}, Title: "e", lower: " f "}
+	if v.Name != want.Name || v.In != want.In || *v.Ptr != *want.Ptr || v.Title != want.Title || v.lower != want.lower {
+		t.Errorf("got %+v", v)
+	}
+
+	if err := New().TransformStructFields(v, "trim", nil); err == nil {
+		t.Error("expected error for non-pointer")
+	}
+}
+
+func TestTransformStructFieldsPointers(t *testing.T) {
+	type node struct {
+		Name  string
+		Next  *node
+		Other *node
+	}
+	calls := 0
+	y := New(Handler("count", func(s string) (string, error) {
+		calls++
+		return s + "!", nil
+	}))
+
+	n := &node{Name: "a"}
+	n.Next = n
+	if err := y.TransformStructFields(n, "count", nil); err != nil {
+		t.Fatal(err)
+	}
+	if n.Name != "a!" || calls != 1 {
+		t.Errorf("cycle: got %q after %d calls", n.Name, calls)
+	}
+
+	calls = 0
+	shared := &node{Name: "s"}
+	m := &node{Name: "m", Next: shared, Other: shared}
+	if err := y.TransformStructFields(m, "count", nil); err != nil {
+		t.Fatal(err)
+	}
+	if m.Name != "m!" || shared.Name != "s!" || calls != 2 {
+		t.Errorf("shared: got %q and %q after %d calls", m.Name, shared.Name, calls)
+	}
+}
+
+func TestTransformStruct(t *testing.T) {
+	type record struct {
+		Title string `transform:"trim"`
+		Slug  string `transform:"from=Title,downcase"`
+	}
+
+	r := record{Title: "  Hello World  "}
+	if err := New().TransformStruct(&r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Title != "Hello World" || r.Slug != "hello world" {
+		t.Errorf("got %+v", r)
+	}
+
+	y := New(RuleSeparator(';'))
+	type sepRecord struct {
+		Title string `transform:"trim"`
+		Code  string `transform:"from=Title;resep: :,;upcase"`
+	}
+	sr := sepRecord{Title: "  Hello World  "}
+	if err := y.TransformStruct(&sr); err != nil {
+		t.Fatal(err)
+	}
+	if sr.Code != "HELLO,WORLD" {
+		t.Errorf("got %q, want %q", sr.Code, "HELLO,WORLD")
+	}
+
+	type cyclic struct {
+		A string `transform:"from=B"`
+		B string `transform:"from=A"`
+	}
+	if err := New().TransformStruct(&cyclic{}); err == nil {
+		t.Error("expected error for cyclic field dependency")
+	}
+}
+
+func TestTransformStructPointers(t *testing.T) {
+	type node struct {
+		Name  string `transform:"count"`
+		Next  *node
+		Other *node
+	}
+	calls := 0
+	y := New(Handler("count", func(s string) (string, error) {
+		calls++
+		return s + "!", nil
+	}))
+
+	n := &node{Name: "a"}
+	n.Next = n
+	if err := y.TransformStruct(n); err != nil {
+		t.Fatal(err)
+	}
+	if n.Name != "a!" || calls != 1 {
+		t.Errorf("cycle: got %q after %d calls", n.Name, calls)
+	}
+
+	calls = 0
+	shared := &node{Name: "s"}
+	m := &node{Name: "m", Next: shared, Other: shared}
+	if err := y.TransformStruct(m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Name != "m!" || shared.Name != "s!" || calls != 2 {
+		t.Errorf("shared: got %q and %q after %d calls", m.Name, shared.Name, calls)
+	}
+}
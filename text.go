@@ -0,0 +1,682 @@
+package transform
+
+import (
+	"html"
+	"math/rand"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/width"
+)
+
+// KVGet returns a function that parses strings of key/value pairs like
+// `k1=v1 k2="v 2"` and returns the value of the given key. Pairs are
+// separated by sep (any whitespace if empty) and keys and values by assign
+// ("=" if empty). Values may be enclosed in double quotes, in which case they
+// may contain separators and backslash-escaped quotes. An error is returned
+// if the key is not present.
+func (*Transform) KVGet(key, sep, assign string) TransformFunc {
+	if assign == "" {
+		assign = "="
+	}
+	isSep := func(s string) (int, bool) {
+		if sep == "" {
+			if r, n := utf8.DecodeRuneInString(s); n > 0 && unicode.IsSpace(r) {
+				return n, true
+			}
+			return 0, false
+		}
+		return len(sep), strings.HasPrefix(s, sep)
+	}
+
+	return func(s string) (string, error) {
+		for s != "" {
+			if n, ok := isSep(s); ok {
+				s = s[n:]
+				continue
+			}
+
+			// Read the key up to the assignment or the next separator.
+			i := 0
+			for i < len(s) && !strings.HasPrefix(s[i:], assign) {
+				if _, ok := isSep(s[i:]); ok {
+					break
+				}
+				i++
+			}
+			k := strings.TrimSpace(s[:i])
+			s = s[i:]
+			if !strings.HasPrefix(s, assign) {
+				continue
+			}
+			s = s[len(assign):]
+
+			// Read the value, which may be quoted.
+			var val string
+			if strings.HasPrefix(s, `"`) {
+				var b strings.Builder
+				i = 1
+				for ; i < len(s) && s[i] != '"'; i++ {
+					if s[i] == '\\' && i+1 < len(s) {
+						i++
+					}
+					b.WriteByte(s[i])
+				}
+				if i == len(s) {
+					return "", errors.New("kvget: unterminated quoted value for key: " + k)
+				}
+				val = b.String()
+				s = s[i+1:]
+			} else {
+				i = 0
+				for i < len(s) {
+					if _, ok := isSep(s[i:]); ok {
+						break
+					}
+					i++
+				}
+				val = s[:i]
+				s = s[i:]
+			}
+
+			if k == key {
+				return val, nil
+			}
+		}
+		return "", errors.New("kvget: key not found: " + key)
+	}
+}
+
+// splitLines splits a string into lines, each including its line ending
+// ("\n", "\r\n" or "\r"). The last line has no line ending if the string does
+// not end with one.
+func splitLines(s string) []string {
+	var lines []string
+	for s != "" {
+		i := strings.IndexAny(s, "\r\n")
+		if i == -1 {
+			lines = append(lines, s)
+			break
+		}
+		n := i + 1
+		if s[i] == '\r' && n < len(s) && s[n] == '\n' {
+			n++
+		}
+		lines = append(lines, s[:n])
+		s = s[n:]
+	}
+	return lines
+}
+
+// MaxLines returns a function that keeps at most n lines of the input,
+// preserving their line endings. If lines are dropped, the marker is appended
+// as an additional line.
+func (*Transform) MaxLines(n int, marker string) TransformFunc {
+	return func(s string) (string, error) {
+		lines := splitLines(s)
+		if len(lines) <= n {
+			return s, nil
+		}
+		return strings.Join(lines[:n], "") + marker, nil
+	}
+}
+
+// runeWidth returns the display width of a rune in a fixed-width terminal:
+// 2 for characters with the East Asian Width property Wide or Fullwidth, 0
+// for nonspacing and enclosing marks and format characters, and 1 otherwise.
+func runeWidth(r rune) int {
+	if unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+		return 0
+	}
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	}
+	return 1
+}
+
+// stringWidth returns the display width of a string (see runeWidth).
+func stringWidth(s string) int {
+	w := 0
+	for _, r := range s {
+		w += runeWidth(r)
+	}
+	return w
+}
+
+// WrapCJK returns a function that wraps lines so that their display width
+// does not exceed the given limit (see runeWidth for the width model).
+// Lines are broken at whitespace and between wide characters, which are
+// typically CJK characters not separated by spaces. Words wider than the
+// limit are not broken. Existing line breaks are preserved.
+func (*Transform) WrapCJK(limit int) TransformFunc {
+	type token struct {
+		s     string
+		space bool
+	}
+
+	return func(s string) (string, error) {
+		var b strings.Builder
+		for i, line := range strings.Split(s, "\n") {
+			if i > 0 {
+				b.WriteByte('\n')
+			}
+
+			var tokens []token
+			var word strings.Builder
+			space := false
+			flush := func() {
+				if word.Len() > 0 {
+					tokens = append(tokens, token{word.String(), space})
+					word.Reset()
+					space = false
+				}
+			}
+			for _, r := range line {
+				switch {
+				case unicode.IsSpace(r):
+					flush()
+					space = true
+				case runeWidth(r) == 2:
+					flush()
+					tokens = append(tokens, token{string(r), space})
+					space = false
+				default:
+					word.WriteRune(r)
+				}
+			}
+			flush()
+
+			w := 0
+			for _, tok := range tokens {
+				tw := stringWidth(tok.s)
+				sep := 0
+				if tok.space && w > 0 {
+					sep = 1
+				}
+				if w > 0 && w+sep+tw > limit {
+					b.WriteByte('\n')
+					w, sep = 0, 0
+				}
+				if sep > 0 {
+					b.WriteByte(' ')
+				}
+				b.WriteString(tok.s)
+				w += sep + tw
+			}
+		}
+		return b.String(), nil
+	}
+}
+
+// lineEndings indexes line endings by name.
+var lineEndings = map[string]string{
+	"lf":   "\n",
+	"crlf": "\r\n",
+	"cr":   "\r",
+}
+
+// normalizeEOL converts all line endings ("\r\n" and "\r") to "\n".
+func normalizeEOL(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.ReplaceAll(s, "\r", "\n")
+}
+
+// EOL returns a function that converts all line endings, including mixed
+// ones, to the given line ending. A trailing line ending is converted like
+// any other.
+func (*Transform) EOL(eol string) TransformFunc {
+	return func(s string) (string, error) {
+		s = normalizeEOL(s)
+		if eol != "\n" {
+			s = strings.ReplaceAll(s, "\n", eol)
+		}
+		return s, nil
+	}
+}
+
+// maxUnescapeIterations limits the number of passes of HTMLUnescapeDeep.
+const maxUnescapeIterations = 10
+
+// HTMLUnescape returns the string with HTML entities unescaped.
+func (*Transform) HTMLUnescape(s string) (string, error) {
+	return html.UnescapeString(s), nil
+}
+
+// HTMLUnescapeDeep unescapes HTML entities repeatedly until the string no
+// longer changes, e.g. to decode "&amp;amp;" to "&". It returns an error if
+// the string is still changing after 10 passes.
+func (*Transform) HTMLUnescapeDeep(s string) (string, error) {
+	for i := 0; i < maxUnescapeIterations; i++ {
+		u := html.UnescapeString(s)
+		if u == s {
+			return s, nil
+		}
+		s = u
+	}
+	if html.UnescapeString(s) != s {
+		return "", errors.New("htmlunescape: too many levels of escaping")
+	}
+	return s, nil
+}
+
+// shufflePerm returns a deterministic permutation of n indices derived from
+// the given seed using a Fisher–Yates shuffle.
+func shufflePerm(n int, seed int64) []int {
+	perm := make([]int, n)
+	for i := range perm {
+		perm[i] = i
+	}
+	rnd := rand.New(rand.NewSource(seed))
+	for i := n - 1; i > 0; i-- {
+		j := rnd.Intn(i + 1)
+		perm[i], perm[j] = perm[j], perm[i]
+	}
+	return perm
+}
+
+// Shuffle returns a function that permutes the runes of the input
+// deterministically based on the given seed. The permutation can be reversed
+// using Unshuffle with the same seed. This is obfuscation, not encryption.
+func (*Transform) Shuffle(seed int64) TransformFunc {
+	return func(s string) (string, error) {
+		rr := []rune(s)
+		out := make([]rune, len(rr))
+		for i, p := range shufflePerm(len(rr), seed) {
+			out[i] = rr[p]
+		}
+		return string(out), nil
+	}
+}
+
+// Unshuffle returns a function that reverses Shuffle with the same seed.
+func (*Transform) Unshuffle(seed int64) TransformFunc {
+	return func(s string) (string, error) {
+		rr := []rune(s)
+		out := make([]rune, len(rr))
+		for i, p := range shufflePerm(len(rr), seed) {
+			out[p] = rr[i]
+		}
+		return string(out), nil
+	}
+}
+
+// unescapeArg resolves the escape sequences \t, \n, \r, \s (space), \\ and
+// \xHH (a byte in hex notation, e.g. \x2c for a comma or \x3a for a colon)
+// in a rule argument.
+func unescapeArg(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' {
+			b.WriteByte(c)
+			continue
+		}
+		if i++; i == len(s) {
+			return "", errors.New("incomplete escape sequence: " + s)
+		}
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 's':
+			b.WriteByte(' ')
+		case '\\':
+			b.WriteByte('\\')
+		case 'x':
+			if i+2 >= len(s) {
+				return "", errors.New("incomplete escape sequence: " + s)
+			}
+			v, err := strconv.ParseUint(s[i+1:i+3], 16, 8)
+			if err != nil {
+				return "", errors.New("invalid escape sequence: " + s)
+			}
+			b.WriteByte(byte(v))
+			i += 2
+		default:
+			return "", errors.New("invalid escape sequence: " + s)
+		}
+	}
+	return b.String(), nil
+}
+
+// Resep returns a function that splits the input at any of the characters in
+// seps, trims the resulting tokens, drops empty ones and joins the rest using
+// the given separator.
+func (*Transform) Resep(seps, sep string) TransformFunc {
+	return func(s string) (string, error) {
+		var tokens []string
+		for _, tok := range strings.FieldsFunc(s, func(r rune) bool { return strings.ContainsRune(seps, r) }) {
+			if tok = strings.TrimSpace(tok); tok != "" {
+				tokens = append(tokens, tok)
+			}
+		}
+		return strings.Join(tokens, sep), nil
+	}
+}
+
+// invisibleRunes lists the runes removed by StripInvisible.
+var invisibleRunes = map[rune]bool{
+	'\u200b': true, // zero width space
+	'\u200c': true, // zero width non-joiner
+	'\u200d': true, // zero width joiner
+	'\u2060': true, // word joiner
+	'\ufeff': true, // zero width no-break space (byte order mark)
+	'\u200e': true, // left-to-right mark
+	'\u200f': true, // right-to-left mark
+	'\u061c': true, // arabic letter mark
+	'\u202a': true, // left-to-right embedding
+	'\u202b': true, // right-to-left embedding
+	'\u202c': true, // pop directional formatting
+	'\u202d': true, // left-to-right override
+	'\u202e': true, // right-to-left override
+	'\u2066': true, // left-to-right isolate
+	'\u2067': true, // right-to-left isolate
+	'\u2068': true, // first strong isolate
+	'\u2069': true, // pop directional isolate
+}
+
+// StripInvisible removes invisible characters from the string: the runes
+// listed in invisibleRunes (zero-width characters, the byte order mark and
+// bidirectional controls) as well as all other characters of the Unicode
+// format category Cf, e.g. the soft hyphen (U+00AD) and the invisible
+// mathematical operators (U+2061-U+2064).
+func (*Transform) StripInvisible(s string) (string, error) {
+	return strings.Map(func(r rune) rune {
+		if invisibleRunes[r] || unicode.Is(unicode.Cf, r) {
+			return -1
+		}
+		return r
+	}, s), nil
+}
+
+// Initials returns a function that returns the uppercased first letters of
+// the words of its input, e.g. "JFK" for "John Fitzgerald Kennedy". Words are
+// separated by whitespace or hyphens. If max is positive, at most max letters
+// are returned.
+func (*Transform) Initials(max int) TransformFunc {
+	return func(s string) (string, error) {
+		return firstLetters(splitWords(s, true), max), nil
+	}
+}
+
+// smallWords lists the words skipped by Acronym in skipSmall mode.
+var smallWords = map[string]bool{
+	"a": true, "an": true, "and": true, "as": true, "at": true, "by": true,
+	"for": true, "from": true, "in": true, "of": true, "on": true, "or": true,
+	"the": true, "to": true, "with": true,
+}
+
+// Acronym returns a function that builds an acronym from the uppercased
+// first letters of the words of its input, e.g. "PDF" for "Portable Document
+// Format". Words are separated by whitespace and, if hyphens is true, by
+// hyphens. If skipSmall is true, small words like "of" or "the" (see
+// smallWords) are skipped unless they are the first word.
+func (*Transform) Acronym(skipSmall, hyphens bool) TransformFunc {
+	return func(s string) (string, error) {
+		words := splitWords(s, hyphens)
+		if skipSmall {
+			var kept []string
+			for i, w := range words {
+				if i == 0 || !smallWords[strings.ToLower(w)] {
+					kept = append(kept, w)
+				}
+			}
+			words = kept
+		}
+		return firstLetters(words, 0), nil
+	}
+}
+
+// splitWords splits a string into words separated by whitespace and,
+// optionally, hyphens.
+func splitWords(s string, hyphens bool) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || hyphens && r == '-'
+	})
+}
+
+// firstLetters returns the uppercased first letters of the given words, at
+// most max letters if max is positive.
+func firstLetters(words []string, max int) string {
+	var b strings.Builder
+	for i, w := range words {
+		if max > 0 && i == max {
+			break
+		}
+		r, _ := utf8.DecodeRuneInString(w)
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+// MinLen returns a function that ensures that its input is at least n runes
+// long. Shorter input results in an error, unless pad is non-empty, in which
+// case the input is left-padded with it to the minimum length.
+func (*Transform) MinLen(n int, pad string) TransformFunc {
+	return func(s string) (string, error) {
+		l := utf8.RuneCountInString(s)
+		if l >= n {
+			return s, nil
+		}
+		if pad == "" {
+			return "", errors.Errorf("minlen: value is shorter than %d characters: %s", n, s)
+		}
+		p := []rune(strings.Repeat(pad, n-l))
+		return string(p[:n-l]) + s, nil
+	}
+}
+
+// Truncate returns a function that cuts its input to at most n runes. If the
+// input is cut, the ellipsis is appended, with the input being cut further so
+// that the result including the ellipsis does not exceed n runes. If the
+// ellipsis has n or more runes, the input is cut without it.
+func (*Transform) Truncate(n int, ellipsis string) TransformFunc {
+	e := utf8.RuneCountInString(ellipsis)
+	if e >= n {
+		ellipsis, e = "", 0
+	}
+	return func(s string) (string, error) {
+		if utf8.RuneCountInString(s) <= n {
+			return s, nil
+		}
+		return string([]rune(s)[:n-e]) + ellipsis, nil
+	}
+}
+
+// TrimLines removes leading and trailing whitespace from each line of the
+// string, preserving the number of lines and their line endings.
+func (*Transform) TrimLines(s string) (string, error) {
+	var b strings.Builder
+	for _, line := range splitLines(s) {
+		content := strings.TrimRight(line, "\r\n")
+		b.WriteString(strings.TrimSpace(content))
+		b.WriteString(line[len(content):])
+	}
+	return b.String(), nil
+}
+
+// AlignTable returns a function that formats multi-line input as a table
+// with aligned columns. Each line is split at sep (runs of whitespace if
+// empty) and the cells are trimmed and padded to the display width of the
+// widest cell in their column. Columns are separated by two spaces. The
+// alignment of each column is given by the corresponding character of
+// aligns, 'l' for left and 'r' for right; columns without one are left
+// aligned. Rows with fewer cells than others are simply shorter.
+func (*Transform) AlignTable(sep, aligns string) TransformFunc {
+	return func(s string) (string, error) {
+		lines := strings.Split(normalizeEOL(s), "\n")
+		trailing := len(lines) > 1 && lines[len(lines)-1] == ""
+		if trailing {
+			lines = lines[:len(lines)-1]
+		}
+
+		rows := make([][]string, len(lines))
+		var widths []int
+		for i, line := range lines {
+			var cells []string
+			if sep == "" {
+				cells = strings.Fields(line)
+			} else {
+				cells = strings.Split(line, sep)
+			}
+			for j := range cells {
+				cells[j] = strings.TrimSpace(cells[j])
+				if j == len(widths) {
+					widths = append(widths, 0)
+				}
+				if w := stringWidth(cells[j]); w > widths[j] {
+					widths[j] = w
+				}
+			}
+			rows[i] = cells
+		}
+
+		var b strings.Builder
+		for i, cells := range rows {
+			if i > 0 {
+				b.WriteByte('\n')
+			}
+			var line strings.Builder
+			for j, c := range cells {
+				if j > 0 {
+					line.WriteString("  ")
+				}
+				pad := strings.Repeat(" ", widths[j]-stringWidth(c))
+				if j < len(aligns) && aligns[j] == 'r' {
+					line.WriteString(pad + c)
+				} else {
+					line.WriteString(c + pad)
+				}
+			}
+			b.WriteString(strings.TrimRight(line.String(), " "))
+		}
+		if trailing {
+			b.WriteByte('\n')
+		}
+		return b.String(), nil
+	}
+}
+
+// normPunct contains the punctuation characters collapsed by NormPunct.
+const normPunct = "!?.,;:"
+
+// NormPunct returns a function that collapses runs of the same punctuation
+// character (one of "!?.,;:") to a single one, e.g. "!!!" to "!". If ellipsis
+// is true, runs of three or more periods are replaced by a single ellipsis
+// character ("…") instead.
+func (*Transform) NormPunct(ellipsis bool) TransformFunc {
+	return func(s string) (string, error) {
+		var b strings.Builder
+		rr := []rune(s)
+		for i := 0; i < len(rr); {
+			r := rr[i]
+			j := i + 1
+			if strings.ContainsRune(normPunct, r) {
+				for j < len(rr) && rr[j] == r {
+					j++
+				}
+			}
+			if r == '.' && ellipsis && j-i > 2 {
+				b.WriteRune('…')
+			} else {
+				b.WriteRune(r)
+			}
+			i = j
+		}
+		return b.String(), nil
+	}
+}
+
+// OneLine returns a function that joins the lines of its input using the
+// given separator. Each line is trimmed and empty lines are dropped.
+func (*Transform) OneLine(sep string) TransformFunc {
+	return func(s string) (string, error) {
+		var lines []string
+		for _, line := range strings.Split(normalizeEOL(s), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				lines = append(lines, line)
+			}
+		}
+		return strings.Join(lines, sep), nil
+	}
+}
+
+// Set returns a function that treats its input as a list separated by sep,
+// and returns the sorted set of its trimmed, non-empty elements joined by
+// sep. Elements are lowercased first if fold is true, so that "B, a, b,"
+// becomes "a,b".
+func (*Transform) Set(sep string, fold bool) TransformFunc {
+	return func(s string) (string, error) {
+		seen := map[string]bool{}
+		var elems []string
+		for _, e := range strings.Split(s, sep) {
+			e = strings.TrimSpace(e)
+			if fold {
+				e = strings.ToLower(e)
+			}
+			if e != "" && !seen[e] {
+				seen[e] = true
+				elems = append(elems, e)
+			}
+		}
+		sort.Strings(elems)
+		return strings.Join(elems, sep), nil
+	}
+}
+
+// UniqLines returns a function that collapses consecutive identical lines
+// into one, like the Unix uniq command. Lines are compared without their
+// line endings. If count is true, each line is prefixed by the number of
+// occurrences and a space. Non-consecutive duplicates are kept.
+func (*Transform) UniqLines(count bool) TransformFunc {
+	return func(s string) (string, error) {
+		var b strings.Builder
+		lines := splitLines(s)
+		for i := 0; i < len(lines); {
+			content := strings.TrimRight(lines[i], "\r\n")
+			j := i + 1
+			for j < len(lines) && strings.TrimRight(lines[j], "\r\n") == content {
+				j++
+			}
+			if count {
+				b.WriteString(strconv.Itoa(j-i) + " ")
+			}
+			b.WriteString(content)
+			last := lines[j-1]
+			b.WriteString(last[len(strings.TrimRight(last, "\r\n")):])
+			i = j
+		}
+		return b.String(), nil
+	}
+}
+
+// bulletRE matches a list marker at the start of a line: "*", "-", "•" or a
+// number followed by a period, each followed by whitespace. The indentation
+// is captured.
+var bulletRE = regexp.MustCompile(`^([ \t]*)(?:[*•-]|[0-9]+\.)[ \t]+`)
+
+// NormBullets returns a function that replaces the list marker of each line
+// (see bulletRE) with the given marker followed by a space, preserving
+// indentation. Lines without a list marker are kept unchanged.
+func (*Transform) NormBullets(marker string) TransformFunc {
+	repl := "${1}" + strings.ReplaceAll(marker, "$", "$$") + " "
+	return func(s string) (string, error) {
+		var b strings.Builder
+		for _, line := range splitLines(s) {
+			b.WriteString(bulletRE.ReplaceAllString(line, repl))
+		}
+		return b.String(), nil
+	}
+}
@@ -0,0 +1,443 @@
+package transform
+
+import (
+	"testing"
+)
+
+func TestKVGet(t *testing.T) {
+	tests := []struct {
+		rule, in, want string
+		ok             bool
+	}{
+		{"kvget:b", "a=1 b=2 c=3", "2", true},
+		{"kvget:b", `a=1 b="x \"y\" z" c=3`, `x "y" z`, true},
+		{"kvget:b:;", "a=1;b=2 3;c=4", "2 3", true},
+		{"kvget:b:&:=>", "a=>1&b=>2", "2", true},
+		{"kvget:city", "city=là name=x", "là", true},
+		{"kvget:name", "city=à name=x", "x", true},
+		{"kvget:city", "city=東京 name=x", "東京", true},
+		{"kvget:b", "a=1\u3000b=東\u00a0c=3", "東", true},
+		{"kvget:d", "a=1 b=2", "", false},
+		{"kvget:b", `b="open`, "", false},
+	}
+	y := New()
+	for _, tt := range tests {
+		got, err := applyRule(y, tt.rule, tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestMaxLines(t *testing.T) {
+	tests := []struct {
+		rule, in, want string
+	}{
+		{"maxlines:2", "a\nb\nc\n", "a\nb\n…"},
+		{"maxlines:2", "a\r\nb\r\nc", "a\r\nb\r\n…"},
+		{"maxlines:2", "a\nb\n", "a\nb\n"},
+		{"maxlines:1:[more]", "a\nb", "a\n[more]"},
+		{"maxlines:3", "", ""},
+	}
+	y := New()
+	for _, tt := range tests {
+		if got, err := applyRule(y, tt.rule, tt.in); err != nil || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+	for _, rule := range []string{"maxlines", "maxlines:0", "maxlines:x"} {
+		if _, err := y.ParseStringRule(rule); err == nil {
+			t.Errorf("%s: expected error", rule)
+		}
+	}
+}
+
+func TestWrapCJK(t *testing.T) {
+	tests := []struct {
+		rule, in, want string
+	}{
+		{"wrapcjk:6", "日本語のテキスト", "日本語\nのテキ\nスト"},
+		{"wrapcjk:10", "hello world foo", "hello\nworld foo"},
+		{"wrapcjk:8", "abc 日本語です", "abc 日本\n語です"},
+		{"wrapcjk:3", "averylongword x", "averylongword\nx"},
+		{"wrapcjk:4", "日本\n語です", "日本\n語で\nす"},
+	}
+	y := New()
+	for _, tt := range tests {
+		if got, err := applyRule(y, tt.rule, tt.in); err != nil || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+	for _, rule := range []string{"wrapcjk", "wrapcjk:0", "wrapcjk:x"} {
+		if _, err := y.ParseStringRule(rule); err == nil {
+			t.Errorf("%s: expected error", rule)
+		}
+	}
+}
+
+func TestEOL(t *testing.T) {
+	tests := []struct {
+		rule, in, want string
+	}{
+		{"eol:lf", "a\r\nb\rc\n", "a\nb\nc\n"},
+		{"eol:crlf", "a\nb\r\nc\r", "a\r\nb\r\nc\r\n"},
+		{"eol:CR", "a\nb", "a\rb"},
+		{"eol:lf", "", ""},
+	}
+	y := New()
+	for _, tt := range tests {
+		if got, err := applyRule(y, tt.rule, tt.in); err != nil || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+	for _, rule := range []string{"eol", "eol:nl"} {
+		if _, err := y.ParseStringRule(rule); err == nil {
+			t.Errorf("%s: expected error", rule)
+		}
+	}
+}
+
+func TestHTMLUnescapeDeep(t *testing.T) {
+	nested := func(n int) string {
+		s := "&"
+		for i := 1; i < n; i++ {
+			s += "amp;"
+		}
+		return s + "lt;"
+	}
+	y := New()
+	for _, n := range []int{1, 2, 10} {
+		if got, err := y.HTMLUnescapeDeep(nested(n)); err != nil || got != "<" {
+			t.Errorf("%d levels: got %q, %v, want %q", n, got, err, "<")
+		}
+	}
+	if _, err := y.HTMLUnescapeDeep(nested(11)); err == nil {
+		t.Error("11 levels: expected error")
+	}
+	if got, err := y.HTMLUnescapeDeep("plain"); err != nil || got != "plain" {
+		t.Errorf("got %q, %v, want %q", got, err, "plain")
+	}
+}
+
+func TestHTMLUnescape(t *testing.T) {
+	tests := []struct {
+		rule, in, want string
+	}{
+		{"htmlunescape", "a &lt;b&gt; &amp;amp; &#39;c&#39;", "a <b> &amp; 'c'"},
+		{"htmlunescape:deep", "&amp;amp;lt;", "<"},
+		{"htmlunescape", "plain", "plain"},
+	}
+	y := New()
+	for _, tt := range tests {
+		if got, err := applyRule(y, tt.rule, tt.in); err != nil || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := y.ParseStringRule("htmlunescape:x"); err == nil {
+		t.Error("expected error for invalid mode")
+	}
+}
+
+func TestShuffle(t *testing.T) {
+	y := New()
+	for _, in := range []string{"", "a", "hello world", "héllo wörld 日本"} {
+		s, err := applyRule(y, "shuffle:42", in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if again, _ := applyRule(y, "shuffle:42", in); again != s {
+			t.Errorf("shuffle(%q) is not deterministic: %q, %q", in, s, again)
+		}
+		if got, err := applyRule(y, "unshuffle:42", s); err != nil || got != in {
+			t.Errorf("unshuffle(%q) = %q, %v, want %q", s, got, err, in)
+		}
+	}
+	if s, _ := applyRule(y, "shuffle:1", "abcdefgh"); s == "abcdefgh" {
+		t.Errorf("shuffle did not permute: %q", s)
+	}
+	for _, rule := range []string{"shuffle", "shuffle:x", "unshuffle:1.5"} {
+		if _, err := y.ParseStringRule(rule); err == nil {
+			t.Errorf("%s: expected error", rule)
+		}
+	}
+}
+
+func TestResep(t *testing.T) {
+	tests := []struct {
+		rule, in, want string
+	}{
+		{`resep:;|\s:\x2c`, "a; b|c  d;;", "a,b,c,d"},
+		{`resep:\x2c:\s/\s`, " a , ,b ", "a / b"},
+		{`resep:\n:\x3a`, "a\n\nb\n", "a:b"},
+	}
+	y := New()
+	for _, tt := range tests {
+		if got, err := applyRule(y, tt.rule, tt.in); err != nil || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+	for _, rule := range []string{"resep", "resep:;", `resep::,`, `resep:\q:,`, `resep:\x2:,`} {
+		if _, err := y.ParseStringRule(rule); err == nil {
+			t.Errorf("%s: expected error", rule)
+		}
+	}
+}
+
+func TestStripInvisible(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"a\u200bb\u200dc\ufeff", "abc"},
+		{"\u202eabc\u202c\u2066x\u2069", "abcx"},
+		{"\u200e\u200f\u061c", ""},
+		{"soft\u00adhyphen", "softhyphen"},
+		{"f\u2061(x)", "f(x)"},
+		{"plain text", "plain text"},
+	}
+	y := New()
+	for _, tt := range tests {
+		if got, err := applyRule(y, "stripinvisible", tt.in); err != nil || got != tt.want {
+			t.Errorf("stripinvisible(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestInitials(t *testing.T) {
+	tests := []struct {
+		rule, in, want string
+	}{
+		{"initials", "John Fitzgerald Kennedy", "JFK"},
+		{"initials", "jean-luc picard", "JLP"},
+		{"initials:2", "John Fitzgerald Kennedy", "JF"},
+		{"initials", "émile zola", "ÉZ"},
+		{"initials", "  ", ""},
+	}
+	y := New()
+	for _, tt := range tests {
+		if got, err := applyRule(y, tt.rule, tt.in); err != nil || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+	for _, rule := range []string{"initials:0", "initials:x"} {
+		if _, err := y.ParseStringRule(rule); err == nil {
+			t.Errorf("%s: expected error", rule)
+		}
+	}
+}
+
+func TestMinLen(t *testing.T) {
+	tests := []struct {
+		rule, in, want string
+		ok             bool
+	}{
+		{"minlen:3", "abc", "abc", true},
+		{"minlen:3", "ab", "", false},
+		{"minlen:3:error", "ab", "", false},
+		{"minlen:5:pad", "ab", "   ab", true},
+		{"minlen:5:pad:0", "42", "00042", true},
+		{"minlen:5:pad:xy", "é", "xyxyé", true},
+		{"minlen:4:pad:xy", "é", "xyxé", true},
+	}
+	y := New()
+	for _, tt := range tests {
+		got, err := applyRule(y, tt.rule, tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+	for _, rule := range []string{"minlen", "minlen:-1", "minlen:3:x", "minlen:3:error:x"} {
+		if _, err := y.ParseStringRule(rule); err == nil {
+			t.Errorf("%s: expected error", rule)
+		}
+	}
+}
+
+func TestTrimLines(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"  a  \n\tb\t\r\n  \nc ", "a\nb\r\n\nc"},
+		{" x \n", "x\n"},
+		{"", ""},
+	}
+	y := New()
+	for _, tt := range tests {
+		if got, err := applyRule(y, "trimlines", tt.in); err != nil || got != tt.want {
+			t.Errorf("trimlines(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestAlignTable(t *testing.T) {
+	tests := []struct {
+		rule, in, want string
+	}{
+		{"table:align", "a bb c\nddd e f\n", "a    bb  c\nddd  e   f\n"},
+		{`table:align:\x2c:lr`, "name,qty\napple, 3\nfig,12", "name   qty\napple    3\nfig     12"},
+		{"table:align:|", "日本|x\nab|y", "日本  x\nab    y"},
+		{"table:align", "a b c\nd", "a  b  c\nd"},
+	}
+	y := New()
+	for _, tt := range tests {
+		if got, err := applyRule(y, tt.rule, tt.in); err != nil || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+	for _, rule := range []string{"table", "table:x", "table:align:,:lc"} {
+		if _, err := y.ParseStringRule(rule); err == nil {
+			t.Errorf("%s: expected error", rule)
+		}
+	}
+}
+
+func TestNormPunct(t *testing.T) {
+	tests := []struct {
+		rule, in, want string
+	}{
+		{"normpunct", "Wow!!! Really??", "Wow! Really?"},
+		{"normpunct", "a,, b;; c::", "a, b; c:"},
+		{"normpunct", "Wait...", "Wait…"},
+		{"normpunct", "Wait..", "Wait."},
+		{"normpunct:noellipsis", "Wait...", "Wait."},
+		{"normpunct", "--- ((x)) ''", "--- ((x)) ''"},
+		{"normpunct", "¡¡Hola!!", "¡¡Hola!"},
+	}
+	y := New()
+	for _, tt := range tests {
+		if got, err := applyRule(y, tt.rule, tt.in); err != nil || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := y.ParseStringRule("normpunct:x"); err == nil {
+		t.Error("expected error for invalid mode")
+	}
+}
+
+func TestAcronym(t *testing.T) {
+	tests := []struct {
+		rule, in, want string
+	}{
+		{"acronym", "Portable Document Format", "PDF"},
+		{"acronym", "light-emitting diode", "LED"},
+		{"acronym:nohyphens", "light-emitting diode", "LD"},
+		{"acronym", "Bank of America", "BOA"},
+		{"acronym:skipsmall", "Bank of America", "BA"},
+		{"acronym:skipsmall", "The Lord of the Rings", "TLR"},
+		{"acronym:skipsmall:nohyphens", "state-of-the-art of war", "SW"},
+	}
+	y := New()
+	for _, tt := range tests {
+		if got, err := applyRule(y, tt.rule, tt.in); err != nil || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := y.ParseStringRule("acronym:x"); err == nil {
+		t.Error("expected error for invalid option")
+	}
+}
+
+func TestOneLine(t *testing.T) {
+	tests := []struct {
+		rule, in, want string
+	}{
+		{"oneline", "  a \n\n b\r\nc  \n", "a b c"},
+		{"oneline:marker", "a\nb", `a\nb`},
+		{"oneline", "\n \n", ""},
+	}
+	y := New()
+	for _, tt := range tests {
+		if got, err := applyRule(y, tt.rule, tt.in); err != nil || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := y.ParseStringRule("oneline:x"); err == nil {
+		t.Error("expected error for invalid mode")
+	}
+}
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		rule, in, want string
+	}{
+		{"set", "b, a, B, c,, a", "a,b,c"},
+		{"set::keepcase", "b, a, B", "B,a,b"},
+		{`set:\s`, "z  y x y", "x y z"},
+		{"set", "", ""},
+	}
+	y := New()
+	for _, tt := range tests {
+		if got, err := applyRule(y, tt.rule, tt.in); err != nil || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+	for _, rule := range []string{"set:,:x", `set:\q`} {
+		if _, err := y.ParseStringRule(rule); err == nil {
+			t.Errorf("%s: expected error", rule)
+		}
+	}
+}
+
+func TestUniqLines(t *testing.T) {
+	tests := []struct {
+		rule, in, want string
+	}{
+		{"uniqlines", "a\na\nb\na\n", "a\nb\na\n"},
+		{"uniqlines", "a\r\na\nb\nb", "a\nb"},
+		{"uniqlines:count", "a\na\nb\n", "2 a\n1 b\n"},
+		{"uniqlines", "", ""},
+	}
+	y := New()
+	for _, tt := range tests {
+		if got, err := applyRule(y, tt.rule, tt.in); err != nil || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := y.ParseStringRule("uniqlines:x"); err == nil {
+		t.Error("expected error for invalid mode")
+	}
+}
+
+func TestNormBullets(t *testing.T) {
+	tests := []struct {
+		rule, in, want string
+	}{
+		{"normbullets", "* a\n  • b\n1. c\n10.\td\n", "- a\n  - b\n- c\n- d\n"},
+		{"normbullets:+", "- a\nplain\n-no space", "+ a\nplain\n-no space"},
+		{"normbullets:$1", "* a", "$1 a"},
+	}
+	y := New()
+	for _, tt := range tests {
+		if got, err := applyRule(y, tt.rule, tt.in); err != nil || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		rule, in, want string
+	}{
+		{"truncate:5", "hello", "hello"},
+		{"truncate:5", "hi", "hi"},
+		{"truncate:5", "", ""},
+		{"truncate:5", "hello world", "hell…"},
+		{"truncate:5", "héllö wörld", "héll…"},
+		{"truncate:5:...", "hello world", "he..."},
+		{"truncate:5:", "hello world", "hello"},
+		{"truncate:4:...", "hello", "h..."},
+		{"truncate:1", "hello", "h"},
+		{"truncate:1", "h", "h"},
+	}
+	y := New()
+	for _, tt := range tests {
+		if got, err := applyRule(y, tt.rule, tt.in); err != nil || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+
+	for _, rule := range []string{"truncate", "truncate:0", "truncate:x", "truncate:1:2:3", "truncate:3:...", "truncate:5: [more]"} {
+		if _, err := y.ParseStringRule(rule); err == nil {
+			t.Errorf("%s: expected error", rule)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package transform
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// relTimeUnits lists the units used to humanize relative times, from the
+// largest to the smallest. A duration is expressed in the largest unit it
+// reaches, truncated to whole units; anything below a minute is "just now".
+var relTimeUnits = []struct {
+	d    time.Duration
+	name string
+}{
+	{365 * 24 * time.Hour, "year"},
+	{30 * 24 * time.Hour, "month"},
+	{7 * 24 * time.Hour, "week"},
+	{24 * time.Hour, "day"},
+	{time.Hour, "hour"},
+	{time.Minute, "minute"},
+}
+
+// Clock returns an option func that sets the function used to determine the
+// current time, e.g. to get deterministic results from time-relative
+// handlers.
+func Clock(f func() time.Time) TransformOption {
+	return func(t *Transform) {
+		t.Clock = f
+	}
+}
+
+// now returns the current time according to the configured clock.
+func (t *Transform) now() time.Time {
+	if t.Clock != nil {
+		return t.Clock()
+	}
+	return time.Now()
+}
+
+// RelTime parses an RFC3339 timestamp and returns the time relative to now in
+// a human readable form, e.g. "3 hours ago" or "in 2 days". The thresholds
+// are:
+//
+//	< 1 minute   just now
+//	< 1 hour     N minutes
+//	< 1 day      N hours
+//	< 7 days     N days
+//	< 30 days    N weeks
+//	< 365 days   N months
+//	otherwise    N years
+func (t *Transform) RelTime(s string) (string, error) {
+	ts, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return "", errors.Wrap(err, "reltime")
+	}
+	return humanizeDuration(t.now().Sub(ts)), nil
+}
+
+// humanizeDuration returns a human readable form of the given duration, which
+// is considered to be in the past if positive and in the future if negative.
+func humanizeDuration(d time.Duration) string {
+	future := d < 0
+	if future {
+		d = -d
+	}
+	for _, u := range relTimeUnits {
+		if d < u.d {
+			continue
+		}
+		n := int64(d / u.d)
+		str := strconv.FormatInt(n, 10) + " " + u.name
+		if n != 1 {
+			str += "s"
+		}
+		if future {
+			return "in " + str
+		}
+		return str + " ago"
+	}
+	return "just now"
+}
+
+// TZ returns a function that parses an RFC3339 timestamp, converts it to the
+// given location and returns it formatted as RFC3339.
+func (*Transform) TZ(loc *time.Location) TransformFunc {
+	return func(s string) (string, error) {
+		ts, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return "", errors.Wrap(err, "tz")
+		}
+		return ts.In(loc).Format(time.RFC3339), nil
+	}
+}
+
+// DateOr returns a function that returns its input unchanged if it can be
+// parsed using the given Go time layout, and the default value otherwise.
+func (*Transform) DateOr(layout, def string) TransformFunc {
+	return func(s string) (string, error) {
+		if _, err := time.Parse(layout, s); err != nil {
+			return def, nil
+		}
+		return s, nil
+	}
+}
+
+// minEpoch and maxEpoch are the Unix epoch seconds of the first and last
+// second representable in RFC3339, i.e. with a four-digit year.
+var (
+	minEpoch = time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+	maxEpoch = time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC).Unix()
+)
+
+// FromEpoch returns a function that converts Unix epoch seconds, or
+// milliseconds if ms is set, to an RFC3339 timestamp in UTC. Fractional
+// seconds are included if the milliseconds are non-zero. Epochs outside of
+// the years 0000-9999 result in an error.
+func (*Transform) FromEpoch(ms bool) TransformFunc {
+	return func(s string) (string, error) {
+		n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+		if err != nil {
+			return "", errors.New("fromepoch: invalid epoch: " + s)
+		}
+		lo, hi := minEpoch, maxEpoch
+		if ms {
+			lo, hi = lo*1000, hi*1000+999
+		}
+		if n < lo || n > hi {
+			return "", errors.New("fromepoch: epoch outside of the years 0000-9999: " + s)
+		}
+		ts := time.Unix(n, 0)
+		if ms {
+			ts = time.UnixMilli(n)
+		}
+		return ts.UTC().Format(time.RFC3339Nano), nil
+	}
+}
+
+// ToEpoch parses an RFC3339 timestamp and returns it as Unix epoch seconds.
+// Fractional seconds are truncated.
+func (*Transform) ToEpoch(s string) (string, error) {
+	ts, err := time.Parse(time.RFC3339, strings.TrimSpace(s))
+	if err != nil {
+		return "", errors.Wrap(err, "toepoch")
+	}
+	return strconv.FormatInt(ts.Unix(), 10), nil
+}
@@ -0,0 +1,123 @@
+package transform
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRelTime(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in, want string
+	}{
+		{"2024-05-10T11:55:00Z", "5 minutes ago"},
+		{"2024-05-10T11:00:00Z", "1 hour ago"},
+		{"2024-05-08T12:00:00Z", "2 days ago"},
+		{"2024-04-19T12:00:00Z", "3 weeks ago"},
+		{"2024-01-10T12:00:00Z", "4 months ago"},
+		{"2021-05-10T12:00:00Z", "3 years ago"},
+		{"2024-05-10T15:00:00Z", "in 3 hours"},
+		{"2024-05-10T14:00:00+02:00", "just now"},
+		{"2024-05-10T11:59:01Z", "just now"},
+		{"2024-05-10T12:00:30Z", "just now"},
+		{"2024-05-10T11:59:00Z", "1 minute ago"},
+	}
+	y := New(Clock(func() time.Time { return now }))
+	for _, tt := range tests {
+		if got, err := applyRule(y, "reltime", tt.in); err != nil || got != tt.want {
+			t.Errorf("reltime(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := applyRule(y, "reltime", "yesterday"); err == nil {
+		t.Error("expected error for invalid timestamp")
+	}
+}
+
+func TestTZ(t *testing.T) {
+	tests := []struct {
+		rule, in, want string
+	}{
+		{"tz:UTC", "2024-05-10T14:00:00+02:00", "2024-05-10T12:00:00Z"},
+		{"tz:Europe/Berlin", "2024-01-10T12:00:00Z", "2024-01-10T13:00:00+01:00"},
+		{"tz:Europe/Berlin", "2024-07-10T12:00:00Z", "2024-07-10T14:00:00+02:00"},
+	}
+	y := New()
+	for _, tt := range tests {
+		if got, err := applyRule(y, tt.rule, tt.in); err != nil || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := y.ParseStringRule("tz:Nowhere/Special"); err == nil {
+		t.Error("expected error for unknown location")
+	}
+	if _, err := applyRule(y, "tz:UTC", "2024-05-10"); err == nil {
+		t.Error("expected error for invalid timestamp")
+	}
+}
+
+func TestDateOr(t *testing.T) {
+	tests := []struct {
+		rule, in, want string
+	}{
+		{"dateor:2006-01-02|unknown", "2024-05-10", "2024-05-10"},
+		{"dateor:2006-01-02|unknown", "10.05.2024", "unknown"},
+		{"dateor:15:04|", "25:00", ""},
+		{"dateor:15:04|", "23:59", "23:59"},
+	}
+	y := New()
+	for _, tt := range tests {
+		if got, err := applyRule(y, tt.rule, tt.in); err != nil || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+	for _, rule := range []string{"dateor", "dateor:2006-01-02", "dateor:|x"} {
+		if _, err := y.ParseStringRule(rule); err == nil {
+			t.Errorf("%s: expected error", rule)
+		}
+	}
+}
+
+func TestFromEpoch(t *testing.T) {
+	tests := []struct {
+		rule, in, want string
+		ok             bool
+	}{
+		{"fromepoch", "0", "1970-01-01T00:00:00Z", true},
+		{"fromepoch", "1700000000", "2023-11-14T22:13:20Z", true},
+		{"fromepoch:ms", "1700000000123", "2023-11-14T22:13:20.123Z", true},
+		{"fromepoch", "253402300799", "9999-12-31T23:59:59Z", true},
+		{"fromepoch", "-62167219200", "0000-01-01T00:00:00Z", true},
+		{"fromepoch:ms", "253402300799999", "9999-12-31T23:59:59.999Z", true},
+		{"fromepoch", "253402300800", "", false},
+		{"fromepoch", "-62167219201", "", false},
+		{"fromepoch", "99999999999999", "", false},
+		{"fromepoch", "9223372036854775807", "", false},
+		{"fromepoch:ms", "253402300800000", "", false},
+	}
+	y := New()
+	for _, tt := range tests {
+		got, err := applyRule(y, tt.rule, tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestToEpoch(t *testing.T) {
+	tests := []struct {
+		in, want string
+		ok       bool
+	}{
+		{"1970-01-01T00:00:00Z", "0", true},
+		{" 2023-11-14T23:13:20.999+01:00 ", "1700000000", true},
+		{"1969-12-31T23:59:59Z", "-1", true},
+		{"2023-11-14", "", false},
+	}
+	y := New()
+	for _, tt := range tests {
+		got, err := applyRule(y, "toepoch", tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("toepoch(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
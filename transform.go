@@ -1,9 +1,12 @@
 package transform
 
 import (
+	"io"
 	"os"
 	"regexp"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/pkg/errors"
 )
@@ -11,6 +14,9 @@ import (
 const (
 	// ShellVar matches ${KEY}
 	ShellVar = `(?i)\${\s*(?P<key>[A-Z0-9_]+)\s*}`
+
+	// MustacheVar matches {{KEY}}
+	MustacheVar = `(?i){{\s*(?P<key>[A-Z0-9_]+)\s*}}`
 )
 
 // TransformFunc takes a string and applies a transformation.
@@ -19,6 +25,15 @@ type TransformFunc func(string) (string, error)
 // Handlers indexes transformation functions by a string tag.
 type Handlers map[string]TransformFunc
 
+// ArgTransformFunc takes the arguments of a string rule, i.e. everything
+// after the first colon, and returns the corresponding transformation
+// function.
+type ArgTransformFunc func(arg string) (TransformFunc, error)
+
+// ArgHandlers indexes transformation functions taking arguments by a string
+// tag.
+type ArgHandlers map[string]ArgTransformFunc
+
 // TransformOption is an option func that is applied to the Transform struct
 // on instantiation.
 type TransformOption func(*Transform)
@@ -44,6 +59,39 @@ func Handler(tag string, f TransformFunc) TransformOption {
 			} else {
 				t.Handlers[tag] = f
 			}
+			delete(t.defaultTags, tag)
+		}
+	}
+}
+
+// ArgHandler returns an option func that registers a new transformation
+// handler taking arguments. A handler registered with Handler under the same
+// tag takes precedence.
+func ArgHandler(tag string, f ArgTransformFunc) TransformOption {
+	return func(t *Transform) {
+		if tag != "" {
+			tag = strings.ToLower(tag)
+			if t.ArgHandlers == nil {
+				t.ArgHandlers = ArgHandlers{}
+			}
+
+			if f == nil {
+				delete(t.ArgHandlers, tag)
+			} else {
+				t.ArgHandlers[tag] = f
+			}
+			delete(t.defaultArgTags, tag)
+		}
+	}
+}
+
+// WithHandlers returns an option func that registers all transformation
+// handlers of the given map. As with Handler, nil functions remove the
+// corresponding handler.
+func WithHandlers(m map[string]TransformFunc) TransformOption {
+	return func(t *Transform) {
+		for tag, f := range m {
+			Handler(tag, f)(t)
 		}
 	}
 }
@@ -52,26 +100,260 @@ func Handler(tag string, f TransformFunc) TransformOption {
 func Rule(ff ...TransformFunc) TransformOption {
 	return func(t *Transform) {
 		t.Rules = append(t.Rules, ff...)
+		t.ruleSrc = append(t.ruleSrc, make([]string, len(ff))...)
 	}
 }
 
 // ExpandEnv adds options to expand environment variables.
 func ExpandEnv() TransformOption {
 	return func(t *Transform) {
-		f, err := t.ParseStringRule("expand:" + ShellVar)
+		rule := "expand:" + ShellVar
+		f, err := t.ParseStringRule(rule)
 		if err != nil {
 			panic(err)
 		}
 		t.Rules = append(t.Rules, f)
+		t.ruleSrc = append(t.ruleSrc, rule)
 		t.Lookups = append(t.Lookups, LookupEnv())
 	}
 }
 
+// NamedLookup returns an option func that registers a lookup function under
+// the given name for use by handlers such as "map". A nil function removes
+// the registration.
+func NamedLookup(name string, f LookupFunc) TransformOption {
+	return func(t *Transform) {
+		if t.NamedLookups == nil {
+			t.NamedLookups = map[string]LookupFunc{}
+		}
+		if f == nil {
+			delete(t.NamedLookups, name)
+		} else {
+			t.NamedLookups[name] = f
+		}
+	}
+}
+
+// LookupTransform returns an option func that applies f to every value
+// resolved by a lookup function before it is substituted by Expand. Empty
+// values are not passed to f, and a value that f turns into an empty string
+// is treated as unresolved, i.e. Expand fails with "could not resolve
+// variable" unless a default is given. There is no separate option for
+// trimming lookup values; f is the place for such cleanup.
+func LookupTransform(f TransformFunc) TransformOption {
+	return func(t *Transform) {
+		t.LookupTransform = f
+	}
+}
+
+// FailOpen returns an option func that makes Transform return the original
+// string instead of an error if any rule fails. The optional hook is called
+// with the original string and the error, e.g. for logging. Errors are not
+// aggregated: the chain stops at the first failing rule, and the hook is
+// called once with that error. If EnsureUTF8 is set and the original string
+// is not valid UTF-8, the error is returned instead.
+func FailOpen(hook func(s string, err error)) TransformOption {
+	return func(t *Transform) {
+		t.FailOpen = true
+		t.FailOpenHook = hook
+	}
+}
+
+// LookupTrace returns an option func that registers a function that is called
+// by Expand whenever a lookup function resolves a key. It receives the key,
+// the resolved value and the index of the lookup function in the chain.
+func LookupTrace(f func(key, value string, lookupIndex int)) TransformOption {
+	return func(t *Transform) {
+		t.LookupTrace = f
+	}
+}
+
+// RecoverPanics returns an option func that makes Transform recover from
+// panics in transformation functions and return them as errors naming the
+// index of the rule. The error includes the stack trace of the panic, which
+// is printed when formatting it with %+v. By default, panics are propagated.
+func RecoverPanics() TransformOption {
+	return func(t *Transform) {
+		t.RecoverPanics = true
+	}
+}
+
+// profile lists the tags of the default handlers of a profile.
+type profile struct {
+	handlers    []string
+	argHandlers []string
+}
+
+// profiles indexes the default handlers installed by ResetHandlers and
+// ResetArgHandlers by profile name. The "full" profile, which is the default,
+// installs all handlers.
+var profiles = map[string]profile{
+	"minimal": {
+		handlers:    []string{"", "nop", "trim"},
+		argHandlers: []string{"expand"},
+	},
+	"standard": {
+		handlers:    []string{"", "nop", "trim", "downcase", "upcase", "capitalize"},
+		argHandlers: []string{"expand", "mustache", "map"},
+	},
+}
+
+// DefaultProfile returns an option func that selects the set of default
+// handlers installed by ResetHandlers and ResetArgHandlers: "minimal",
+// "standard" or "full". All profiles include the "expand" handler. Unknown
+// names install the handlers of the "minimal" profile and make
+// ParseStringRule fail. The profile is kept by Reset. As it resets the
+// handlers, the option should precede options registering handlers.
+func DefaultProfile(name string) TransformOption {
+	return func(t *Transform) {
+		t.Profile = name
+		t.ResetHandlers()
+		t.ResetArgHandlers()
+	}
+}
+
+// profile returns the selected profile and whether it restricts the default
+// handlers. Unknown profiles select the "minimal" profile.
+func (t *Transform) profile() (profile, bool) {
+	if t.Profile == "" || t.Profile == "full" {
+		return profile{}, false
+	}
+	if p, ok := profiles[t.Profile]; ok {
+		return p, true
+	}
+	return profiles["minimal"], true
+}
+
+// checkProfile returns an error if the selected profile is unknown.
+func (t *Transform) checkProfile() error {
+	if _, ok := profiles[t.Profile]; !ok && t.Profile != "" && t.Profile != "full" {
+		return errors.New("unknown profile: " + t.Profile)
+	}
+	return nil
+}
+
+// MemoizeLookups returns an option func that makes Expand resolve each key
+// only once per expanded string. The memoized values only live for a single
+// invocation of the expand function, so nothing is shared between calls or
+// goroutines.
+func MemoizeLookups() TransformOption {
+	return func(t *Transform) {
+		t.MemoizeLookups = true
+	}
+}
+
+// StopIfEmpty returns an option func that makes Transform skip the remaining
+// rules as soon as a rule returns an empty string. Rules that should handle
+// empty values, e.g. ones providing a default, must therefore come before
+// the rule that may empty the value, or the option must not be used.
+func StopIfEmpty() TransformOption {
+	return func(t *Transform) {
+		t.StopIfEmpty = true
+	}
+}
+
+// EnsureUTF8 returns an option func that makes Transform return an error if
+// the result of the rule chain is not valid UTF-8. This also applies in
+// fail-open mode (see FailOpen), which only falls back to the original
+// string if it is valid UTF-8.
+func EnsureUTF8() TransformOption {
+	return func(t *Transform) {
+		t.EnsureUTF8 = true
+	}
+}
+
+// BetweenRules returns an option func that registers a transformation
+// function that Transform applies between each pair of consecutive rules.
+func BetweenRules(f TransformFunc) TransformOption {
+	return func(t *Transform) {
+		t.BetweenRules = f
+	}
+}
+
+// RuleSeparator returns an option func that sets the character separating
+// multiple rules in a string passed to AddStringRules, which defaults to a
+// comma.
+func RuleSeparator(sep rune) TransformOption {
+	return func(t *Transform) {
+		t.RuleSeparator = sep
+	}
+}
+
+// FallbackHandler returns an option func that registers a function to
+// resolve tags for which no handler is registered. Errors returned by it are
+// passed on by ParseStringRule; a nil function without an error means that
+// the tag is unknown.
+func FallbackHandler(f func(tag string) (TransformFunc, error)) TransformOption {
+	return func(t *Transform) {
+		t.FallbackHandler = f
+	}
+}
+
 // Transform holds transformation configuration.
 type Transform struct {
-	Handlers Handlers
-	Lookups  []LookupFunc
-	Rules    []TransformFunc
+	Handlers    Handlers
+	ArgHandlers ArgHandlers
+	Lookups     []LookupFunc
+	Rules       []TransformFunc
+
+	// RuleStrings records the rule strings added by AddStringRules.
+	RuleStrings []string
+
+	// RuleSeparator separates rules in rule strings, defaults to ','.
+	RuleSeparator rune
+
+	// Profile selects the default handlers (see DefaultProfile).
+	Profile string
+
+	// FallbackHandler, if set, resolves otherwise unknown tags.
+	FallbackHandler func(tag string) (TransformFunc, error)
+
+	// NamedLookups indexes lookup functions by name (see NamedLookup).
+	NamedLookups map[string]LookupFunc
+
+	// LookupTransform, if set, is applied to each resolved lookup value.
+	LookupTransform TransformFunc
+
+	// LookupTrace, if set, is called for each successful lookup.
+	LookupTrace func(key, value string, lookupIndex int)
+
+	// MemoizeLookups makes Expand resolve repeated keys only once per string.
+	MemoizeLookups bool
+
+	// Clock, if set, returns the current time for time-relative handlers.
+	Clock func() time.Time
+
+	// RandSource, if set, provides random bytes for random handlers.
+	RandSource io.Reader
+
+	// FailOpen makes Transform return the original string on errors, after
+	// calling FailOpenHook if set.
+	FailOpen     bool
+	FailOpenHook func(s string, err error)
+
+	// RecoverPanics makes Transform convert panics in rules into errors.
+	RecoverPanics bool
+
+	// BetweenRules, if set, is applied between consecutive rules.
+	BetweenRules TransformFunc
+
+	// StopIfEmpty makes Transform stop once a rule returns an empty string.
+	StopIfEmpty bool
+
+	// EnsureUTF8 makes Transform fail if the result is not valid UTF-8.
+	EnsureUTF8 bool
+
+	// defaultTags and defaultArgTags record the tags of Handlers and
+	// ArgHandlers that still hold the default handlers (see Compile).
+	defaultTags    map[string]bool
+	defaultArgTags map[string]bool
+
+	// ruleSrc records the string rule each of Rules was parsed from, or ""
+	// for rules given as functions (see Compile).
+	ruleSrc []string
+
+	metrics  *ruleMetrics
+	interner *interner
 }
 
 // New returns a new transformation configuration.
@@ -81,9 +363,12 @@ func New(ff ...TransformOption) *Transform {
 	return t
 }
 
-// Reset resets a transformation configuration to its default state.
+// Reset resets a transformation configuration to its default state. The
+// selected profile (see DefaultProfile) is kept.
 func (t *Transform) Reset(ff ...TransformOption) *Transform {
+	*t = Transform{Profile: t.Profile}
 	t.ResetHandlers()
+	t.ResetArgHandlers()
 	t.ResetLookups()
 	t.ResetRules()
 	for _, f := range ff {
@@ -94,17 +379,140 @@ func (t *Transform) Reset(ff ...TransformOption) *Transform {
 
 // Reset resets registered transformation handlers to their default state.
 func (t *Transform) ResetHandlers() *Transform {
-	t.Handlers = Handlers{
-		"":           t.NOP,
-		"nop":        t.NOP,
-		"trim":       t.Trim,
-		"downcase":   t.Downcase,
-		"upcase":     t.Upcase,
-		"capitalize": t.Capitalize,
+	t.Handlers = t.defaultHandlers()
+
+	if p, ok := t.profile(); ok {
+		h := Handlers{}
+		for _, tag := range p.handlers {
+			h[tag] = t.Handlers[tag]
+		}
+		t.Handlers = h
+	}
+	t.defaultTags = map[string]bool{}
+	for tag := range t.Handlers {
+		t.defaultTags[tag] = true
+	}
+	return t
+}
+
+// defaultHandlers returns all default transformation handlers, bound to t.
+func (t *Transform) defaultHandlers() Handlers {
+	return Handlers{
+		"":                t.NOP,
+		"nop":             t.NOP,
+		"trim":            t.Trim,
+		"downcase":        t.Downcase,
+		"upcase":          t.Upcase,
+		"capitalize":      t.Capitalize,
+		"reltime":         t.RelTime,
+		"crc32":           t.CRC32,
+		"crc32c":          t.CRC32C,
+		"fragescape":      t.FragEscape,
+		"dedupext":        t.DedupExt,
+		"uuidnorm":        t.UUIDNorm,
+		"urlbase":         t.URLBase,
+		"notbool":         t.NotBool,
+		"regexquote":      t.RegexQuote,
+		"stripinvisible":  t.StripInvisible,
+		"mac":             t.MAC,
+		"ordinal":         t.Ordinal,
+		"base64text":      t.Base64Text,
+		"iso3166":         t.ISO3166,
+		"iso4217":         t.ISO4217,
+		"trimlines":       t.TrimLines,
+		"yamlescape":      t.YAMLEscape,
+		"pathescape":      t.PathEscape,
+		"pathunescape":    t.PathUnescape,
+		"regexclassquote": t.RegexClassQuote,
+		"jsoncanon":       t.JSONCanon,
+		"constant":        t.Constant,
+		"screaming_snake": t.Constant,
+		"stringcolor":     t.StringColor,
+		"joinpath":        t.JoinPath,
+		"splitpath":       t.SplitPath,
+		"emailmask":       t.EmailMask,
+		"urlnormalize":    t.URLNormalize,
+		"stripbom":        t.StripBOM,
+		"toepoch":         t.ToEpoch,
+	}
+}
+
+// ResetArgHandlers resets registered transformation handlers taking
+// arguments to their default state.
+func (t *Transform) ResetArgHandlers() *Transform {
+	t.ArgHandlers = t.defaultArgHandlers()
+
+	if p, ok := t.profile(); ok {
+		h := ArgHandlers{}
+		for _, tag := range p.argHandlers {
+			h[tag] = t.ArgHandlers[tag]
+		}
+		t.ArgHandlers = h
+	}
+	t.defaultArgTags = map[string]bool{}
+	for tag := range t.ArgHandlers {
+		t.defaultArgTags[tag] = true
 	}
 	return t
 }
 
+// defaultArgHandlers returns all default transformation handlers taking
+// arguments, bound to t.
+func (t *Transform) defaultArgHandlers() ArgHandlers {
+	return ArgHandlers{
+		"expand":       t.expandRule,
+		"mustache":     t.mustacheRule,
+		"map":          t.mapRule,
+		"kvget":        t.kvgetRule,
+		"maxlines":     t.maxlinesRule,
+		"wrapcjk":      t.wrapcjkRule,
+		"tristate":     t.tristateRule,
+		"semver":       t.semverRule,
+		"eol":          t.eolRule,
+		"htmlunescape": t.htmlunescapeRule,
+		"coalesce":     t.coalesceRule,
+		"shuffle":      t.shuffleRule,
+		"unshuffle":    t.unshuffleRule,
+		"resep":        t.resepRule,
+		"jsonrequire":  t.jsonrequireRule,
+		"jsonget":      t.jsongetRule,
+		"tz":           t.tzRule,
+		"export":       t.exportRule,
+		"bucket":       t.bucketRule,
+		"percent":      t.percentRule,
+		"toutf8":       t.toutf8Rule,
+		"decode":       t.decodeRule,
+		"encode":       t.encodeRule,
+		"initials":     t.initialsRule,
+		"minlen":       t.minlenRule,
+		"jq":           t.jqRule,
+		"fingerwords":  t.fingerwordsRule,
+		"table":        t.tableRule,
+		"normpunct":    t.normpunctRule,
+		"acronym":      t.acronymRule,
+		"randtoken":    t.randtokenRule,
+		"pathnorm":     t.pathnormRule,
+		"fixeddec":     t.fixeddecRule,
+		"oneline":      t.onelineRule,
+		"urlpath":      t.urlpathRule,
+		"set":          t.setRule,
+		"dateor":       t.dateorRule,
+		"boolsymbol":   t.boolsymbolRule,
+		"uniqlines":    t.uniqlinesRule,
+		"fileperm":     t.filepermRule,
+		"emaillist":    t.emaillistRule,
+		"range":        t.rangeRule,
+		"recase":       t.recaseRule,
+		"plural":       t.pluralRule,
+		"normbullets":  t.normbulletsRule,
+		"stripemoji":   t.stripemojiRule,
+		"port":         t.portRule,
+		"cleanurlpath": t.cleanurlpathRule,
+		"truncate":     t.truncateRule,
+		"fromepoch":    t.fromepochRule,
+	}
+}
+
 // Reset resets lookup functions to defaults.
 func (t *Transform) ResetLookups(ff ...LookupFunc) *Transform {
 	t.Lookups = ff
@@ -114,12 +522,22 @@ func (t *Transform) ResetLookups(ff ...LookupFunc) *Transform {
 // Reset resets transformation rules to defaults.
 func (t *Transform) ResetRules(ff ...TransformFunc) *Transform {
 	t.Rules = ff
+	t.RuleStrings = nil
+	t.ruleSrc = make([]string, len(ff))
 	return t
 }
 
 // ParseStringRule parses a string transformation rule and returns the
-// corresponding transformation func, or an error if there is none.
+// corresponding transformation func, or an error if there is none. Arguments
+// following the tag, separated by a colon, are passed to handlers registered
+// in ArgHandlers, which report an error for an invalid number of arguments.
+// As before handlers taking arguments existed, arguments of other handlers
+// are ignored, e.g. "trim:x" is the same as "trim".
 func (t *Transform) ParseStringRule(rule string) (TransformFunc, error) {
+	if err := t.checkProfile(); err != nil {
+		return nil, err
+	}
+
 	parts := strings.SplitN(rule, ":", 2)
 	tag := strings.ToLower(strings.TrimSpace(parts[0]))
 
@@ -130,20 +548,19 @@ func (t *Transform) ParseStringRule(rule string) (TransformFunc, error) {
 	f := h[tag]
 
 	if f == nil {
-		switch tag {
-		case "expand":
-			if len(parts) == 1 {
-				return nil, errors.New("expand: missing regex")
-			}
-			re, err := regexp.Compile(parts[1])
-			if err != nil {
-				return nil, errors.Wrap(err, "regexp: "+parts[1])
+		if af := t.ArgHandlers[tag]; af != nil {
+			arg := ""
+			if len(parts) > 1 {
+				arg = parts[1]
 			}
-			f, err := t.Expand(re)
-			if err != nil {
-				return nil, err
-			}
-			return f, nil
+			return af(arg)
+		}
+	}
+
+	if f == nil && t.FallbackHandler != nil {
+		var err error
+		if f, err = t.FallbackHandler(tag); err != nil {
+			return nil, err
 		}
 	}
 
@@ -153,21 +570,91 @@ func (t *Transform) ParseStringRule(rule string) (TransformFunc, error) {
 	return f, nil
 }
 
+// Apply parses a single string transformation rule, e.g. "trim" or
+// "maxlines:5", and applies it to the given string.
+func (t *Transform) Apply(rule, s string) (string, error) {
+	f, err := t.ParseStringRule(rule)
+	if err != nil {
+		return "", err
+	}
+	return f(s)
+}
+
+// ruleArgs splits the argument string of a rule into its colon-separated
+// arguments, and ensures that there are at least min and at most max
+// arguments. An empty argument string has no arguments. A negative max means
+// there is no upper limit.
+func ruleArgs(tag string, arg string, min, max int) ([]string, error) {
+	var args []string
+	if arg != "" {
+		args = strings.Split(arg, ":")
+	}
+	if len(args) < min {
+		return nil, errors.Errorf("%s: expected at least %d argument(s), got %d", tag, min, len(args))
+	}
+	if max >= 0 && len(args) > max {
+		return nil, errors.Errorf("%s: expected at most %d argument(s), got %d", tag, max, len(args))
+	}
+	return args, nil
+}
+
 // AddStringRules parses the given string transformation rules and adds the
-// corresponding transformation functions.
+// corresponding transformation functions. Each string may contain multiple
+// rules separated by commas (see RuleSeparator).
 func (t *Transform) AddStringRules(rules ...string) error {
+	src := t.splitStringRules(rules...)
+	ff, err := t.parseRules(src)
+	if err != nil {
+		return err
+	}
+	t.Rules = append(t.Rules, ff...)
+	t.RuleStrings = append(t.RuleStrings, rules...)
+	t.ruleSrc = append(t.ruleSrc, src...)
+	return nil
+}
+
+// parseStringRules parses the given string transformation rules, separated
+// by commas or the configured rule separator, and returns the corresponding
+// transformation functions.
+func (t *Transform) parseStringRules(rules ...string) ([]TransformFunc, error) {
+	return t.parseRules(t.splitStringRules(rules...))
+}
+
+// splitStringRules splits the given string transformation rules at commas or
+// the configured rule separator and returns the non-empty single rules.
+func (t *Transform) splitStringRules(rules ...string) []string {
+	sep := t.ruleSeparator()
+	var res []string
 	for _, r := range rules {
-		for _, s := range strings.Split(r, ",") {
+		for _, s := range strings.Split(r, sep) {
 			if s = strings.TrimSpace(s); s != "" {
-				f, err := t.ParseStringRule(s)
-				if err != nil {
-					return err
-				}
-				t.Rules = append(t.Rules, f)
+				res = append(res, s)
 			}
 		}
 	}
-	return nil
+	return res
+}
+
+// ruleSeparator returns the configured rule separator as a string.
+func (t *Transform) ruleSeparator() string {
+	if t.RuleSeparator != 0 {
+		return string(t.RuleSeparator)
+	}
+	return ","
+}
+
+// parseRules parses the given single string transformation rules and returns
+// the corresponding transformation functions.
+func (t *Transform) parseRules(rules []string) ([]TransformFunc, error) {
+	ff := make([]TransformFunc, 0, len(rules))
+	for _, s := range rules {
+		f, err := t.ParseStringRule(s)
+		if err != nil {
+			return nil, err
+		}
+		ff = append(ff, f)
+	}
+	return ff, nil
 }
 
 // NOP returns the given string unchanged.
@@ -191,19 +678,30 @@ func (*Transform) Upcase(s string) (string, error) {
 }
 
 // Capitalized returns a capitalized version of the given string, i.e., the
-// first character is uppercased and the others lowercased.
+// first character is uppercased and the others lowercased. Multibyte
+// characters are handled correctly.
 func (*Transform) Capitalize(s string) (string, error) {
-	if s == "" {
-		return "", nil
-	}
-	return strings.ToUpper(s[:1]) + strings.ToLower(s[1:]), nil
+	return capitalizeWord(s), nil
 }
 
 // Expand returns a function that replaces patterns by looking up a named key
 // using the given lookup functions. The regular expression must have a
 // parenthesized subexpression called "key" that identifies the key string to
-// look up.
+// look up. If a lookup transform is configured (see LookupTransform), it is
+// applied to each resolved value before substitution.
 func (t *Transform) Expand(re *regexp.Regexp, ff ...LookupFunc) (TransformFunc, error) {
+	return t.expand(re, nil, ff)
+}
+
+// ExpandDefault is like Expand, but keys that cannot be resolved are replaced
+// by the given default value instead of causing an error.
+func (t *Transform) ExpandDefault(re *regexp.Regexp, def string, ff ...LookupFunc) (TransformFunc, error) {
+	return t.expand(re, &def, ff)
+}
+
+// expand implements Expand and ExpandDefault. A nil default means that
+// unresolvable keys are an error.
+func (t *Transform) expand(re *regexp.Regexp, def *string, ff []LookupFunc) (TransformFunc, error) {
 	idx := re.SubexpIndex("key")
 	if idx == -1 {
 		return nil, errors.New("regexp is missing named parenthesized subexpression (?P<key>...): " + re.String())
@@ -214,29 +712,107 @@ func (t *Transform) Expand(re *regexp.Regexp, ff ...LookupFunc) (TransformFunc,
 			return s, nil
 		}
 
-		if len(ff) == 0 {
-			ff = t.Lookups
+		lookups := ff
+		if len(lookups) == 0 {
+			lookups = t.Lookups
+		}
+
+		var memo map[string]string
+		if t.MemoizeLookups {
+			memo = map[string]string{}
 		}
 
-		var s2 string
+		var b strings.Builder
+		b.Grow(len(s))
 		pos := 0
 		for _, m := range matches {
-			var val string
 			key := string(s[m[idx*2]:m[idx*2+1]])
-			for _, f := range ff {
-				if v, ok := f(key); ok {
-					val = v
-					break
+			val, found := memo[key]
+			if !found {
+				var err error
+				if val, err = t.lookup(key, lookups); err != nil {
+					return "", err
+				}
+				if val == "" {
+					if def == nil {
+						return "", errors.New("could not resolve variable: " + key)
+					}
+					val = *def
+				}
+				if memo != nil {
+					memo[key] = val
 				}
 			}
-			if val == "" {
-				return "", errors.New("could not resolve variable: " + key)
+			if t.interner != nil && m[0] == 0 && m[1] == len(s) {
+				return t.interner.intern(val), nil
 			}
-			s2 += s[pos:m[0]] + val
+			b.WriteString(s[pos:m[0]])
+			b.WriteString(val)
 			pos = m[1]
 		}
-		s2 += s[pos:]
-		return s2, nil
+		b.WriteString(s[pos:])
+		return b.String(), nil
+	}, nil
+}
+
+// lookup resolves a key using the given lookup functions and applies the
+// lookup transform, if any. It returns an empty string if the key cannot be
+// resolved.
+func (t *Transform) lookup(key string, lookups []LookupFunc) (string, error) {
+	var val string
+	for i, f := range lookups {
+		if v, ok := f(key); ok {
+			if t.LookupTrace != nil {
+				t.LookupTrace(key, v, i)
+			}
+			val = v
+			break
+		}
+	}
+	if val != "" && t.LookupTransform != nil {
+		var err error
+		if val, err = t.LookupTransform(val); err != nil {
+			return "", errors.Wrap(err, "lookup: "+key)
+		}
+	}
+	return val, nil
+}
+
+// Map returns a function that uses the whole input string as a key and
+// resolves it using the named lookup function. If the key cannot be resolved,
+// the function returns an error, or the input unchanged if keep is true.
+func (t *Transform) Map(name string, keep bool) (TransformFunc, error) {
+	f := t.NamedLookups[name]
+	if f == nil {
+		return nil, errors.New("unknown lookup: " + name)
+	}
+	return func(s string) (string, error) {
+		if v, ok := f(s); ok {
+			return v, nil
+		}
+		if keep {
+			return s, nil
+		}
+		return "", errors.New("could not map value: " + s)
+	}, nil
+}
+
+// Coalesce returns a function that ignores its input and returns the first of
+// the given alternatives whose shell variables (${KEY}) can all be resolved
+// using the configured lookup functions. An alternative without variables,
+// e.g. a trailing literal, always resolves.
+func (t *Transform) Coalesce(alts ...string) (TransformFunc, error) {
+	expand, err := t.Expand(regexp.MustCompile(ShellVar))
+	if err != nil {
+		return nil, err
+	}
+	return func(string) (string, error) {
+		for _, alt := range alts {
+			if v, err := expand(alt); err == nil {
+				return v, nil
+			}
+		}
+		return "", errors.New("coalesce: no alternative could be resolved")
 	}, nil
 }
 
@@ -277,18 +853,75 @@ func LookupStatic(val string) LookupFunc {
 
 // Transform takes a string and applies the given transformation functions to
 // it. If no transformation functions are given, it uses the configured default
-// rules (see Transform.Rules).
+// rules (see Transform.Rules). If fail-open mode is enabled (see FailOpen),
+// an error causes the original string to be returned instead.
 func (t *Transform) Transform(s string, ff ...TransformFunc) (string, error) {
 	if len(ff) == 0 {
 		ff = t.Rules
 	}
+	return t.apply(s, ff)
+}
+
+// callRecover calls the transformation function at index i of a rule chain
+// and converts a panic into an error.
+func callRecover(i int, f TransformFunc, s string) (res string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("panic in rule %d: %v", i, r)
+		}
+	}()
+	return f(s)
+}
+
+// apply applies the given transformation functions to a string.
+func (t *Transform) apply(s string, ff []TransformFunc) (string, error) {
+	orig := s
+	fail := func(err error) (string, error) {
+		if t.FailOpen && (!t.EnsureUTF8 || utf8.ValidString(orig)) {
+			if t.FailOpenHook != nil {
+				t.FailOpenHook(orig, err)
+			}
+			return orig, nil
+		}
+		return "", err
+	}
+
 	var err error
-	for _, f := range ff {
-		if f != nil {
-			if s, err = f(s); err != nil {
-				return "", errors.Wrap(err, "rule")
+	prev := -1
+	for i, f := range ff {
+		if f == nil {
+			continue
+		}
+
+		if prev >= 0 && t.BetweenRules != nil {
+			if s, err = t.BetweenRules(s); err != nil {
+				return fail(errors.Wrapf(err, "between rules %d and %d", prev, i))
 			}
 		}
+		prev = i
+
+		var start time.Time
+		if t.metrics != nil {
+			start = time.Now()
+		}
+		if t.RecoverPanics {
+			s, err = callRecover(i, f, s)
+		} else {
+			s, err = f(s)
+		}
+		if t.metrics != nil {
+			t.metrics.record(i, time.Since(start))
+		}
+
+		if err != nil {
+			return fail(errors.Wrap(err, "rule"))
+		}
+		if s == "" && t.StopIfEmpty {
+			break
+		}
+	}
+	if t.EnsureUTF8 && !utf8.ValidString(s) {
+		return fail(errors.New("result is not valid UTF-8"))
 	}
 	return s, nil
 }
@@ -0,0 +1,492 @@
+package transform
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"github.com/pkg/errors"
+)
+
+// applyRule parses a single string rule and applies it to s.
+func applyRule(y *Transform, rule, s string) (string, error) {
+	f, err := y.ParseStringRule(rule)
+	if err != nil {
+		return "", err
+	}
+	return f(s)
+}
+
+func TestLookupTransform(t *testing.T) {
+	y := New(
+		Lookup(LookupHandlers(map[string]string{"a": " x ", "b": "", "c": "drop"})),
+		LookupTransform(func(s string) (string, error) {
+			if s == "drop" {
+				return "", nil
+			}
+			return strings.ToUpper(strings.TrimSpace(s)), nil
+		}),
+	)
+	tests := []struct {
+		in, want string
+		ok       bool
+	}{
+		{"${a}!", "X!", true},
+		{"${b}", "", false},
+		{"${c}", "", false},
+	}
+	for _, tt := range tests {
+		got, err := applyRule(y, `expand:\$\{(?P<key>\w+)\}`, tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("%q: got %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestMap(t *testing.T) {
+	codes := LookupHandlers(map[string]string{"de": "Germany", "fr": "France"})
+	y := New(NamedLookup("country", codes))
+	tests := []struct {
+		rule, in, want string
+		ok             bool
+	}{
+		{"map:country", "de", "Germany", true},
+		{"map:country", "xx", "", false},
+		{"map:country:keep", "xx", "xx", true},
+		{"map:country:keep", "fr", "France", true},
+	}
+	for _, tt := range tests {
+		got, err := applyRule(y, tt.rule, tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+	for _, rule := range []string{"map", "map:other", "map:country:x"} {
+		if _, err := y.ParseStringRule(rule); err == nil {
+			t.Errorf("%s: expected error", rule)
+		}
+	}
+	if _, err := New(NamedLookup("country", codes), NamedLookup("country", nil)).ParseStringRule("map:country"); err == nil {
+		t.Error("expected error for removed lookup")
+	}
+}
+
+func TestFailOpen(t *testing.T) {
+	fails := func(string) (string, error) { return "", errors.New("failed") }
+	var calls []string
+	y := New(FailOpen(func(s string, err error) {
+		calls = append(calls, s+": "+err.Error())
+	}))
+	if s, err := y.Transform(" a ", y.Trim, fails, y.Upcase, fails); err != nil || s != " a " {
+		t.Errorf("got %q, %v, want %q", s, err, " a ")
+	}
+	if len(calls) != 1 || calls[0] != " a : rule: failed" {
+		t.Errorf("got hook calls %q, want one", calls)
+	}
+	if s, err := y.Transform(" a ", y.Trim, y.Upcase); err != nil || s != "A" {
+		t.Errorf("got %q, %v, want %q", s, err, "A")
+	}
+	if _, err := New(FailOpen(nil)).Transform("a", fails); err != nil {
+		t.Errorf("got error %v without hook", err)
+	}
+	if _, err := New().Transform("a", fails); err == nil {
+		t.Error("expected error without FailOpen")
+	}
+}
+
+func TestWithHandlers(t *testing.T) {
+	exclaim := func(s string) (string, error) { return s + "!", nil }
+	y := New(WithHandlers(map[string]TransformFunc{
+		"Exclaim": exclaim,
+		"upcase":  nil,
+	}))
+	if got, err := applyRule(y, "exclaim", "a"); err != nil || got != "a!" {
+		t.Errorf("got %q, %v, want %q", got, err, "a!")
+	}
+	if _, err := y.ParseStringRule("upcase"); err == nil {
+		t.Error("expected removed handler to be unknown")
+	}
+	if _, err := y.ParseStringRule("downcase"); err != nil {
+		t.Errorf("default handler: %v", err)
+	}
+}
+
+func TestApply(t *testing.T) {
+	y := New()
+	tests := []struct {
+		rule, in, want string
+		ok             bool
+	}{
+		{"trim", " a ", "a", true},
+		{"UPCASE", "a", "A", true},
+		{"maxlines:1:", "a\nb", "a\n", true},
+		{"nosuchrule", "a", "", false},
+		{"maxlines:x", "a", "", false},
+	}
+	for _, tt := range tests {
+		got, err := y.Apply(tt.rule, tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("Apply(%q, %q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestLookupTrace(t *testing.T) {
+	var trace []string
+	y := New(
+		Lookup(
+			LookupHandlers(map[string]string{"a": "1"}),
+			LookupHandlers(map[string]string{"a": "x", "b": "2"}),
+		),
+		LookupTrace(func(key, value string, i int) {
+			trace = append(trace, key+"="+value+"@"+string(rune('0'+i)))
+		}),
+	)
+	s, err := applyRule(y, `expand:\$\{(?P<key>\w+)\}`, "${a}${b}")
+	if err != nil || s != "12" {
+		t.Errorf("got %q, %v, want %q", s, err, "12")
+	}
+	if len(trace) != 2 || trace[0] != "a=1@0" || trace[1] != "b=2@1" {
+		t.Errorf("got trace %q", trace)
+	}
+}
+
+func TestCoalesce(t *testing.T) {
+	y := New(Lookup(LookupHandlers(map[string]string{"host": "example.com", "port": "80"})))
+	tests := []struct {
+		rule, want string
+		ok         bool
+	}{
+		{"coalesce:${url}:${host}", "example.com", true},
+		{"coalesce:${url}:${host}/${nope}:fallback", "fallback", true},
+		{"coalesce:${host}-${port}", "example.com-80", true},
+		{"coalesce:${url}:${nope}", "", false},
+	}
+	for _, tt := range tests {
+		got, err := applyRule(y, tt.rule, "ignored")
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("%s = %q, %v, want %q", tt.rule, got, err, tt.want)
+		}
+	}
+	if _, err := y.ParseStringRule("coalesce"); err == nil {
+		t.Error("expected error without alternatives")
+	}
+}
+
+func TestRecoverPanics(t *testing.T) {
+	boom := func(string) (string, error) { panic("boom") }
+	y := New(RecoverPanics())
+	_, err := y.Transform("a", y.Trim, boom)
+	if err == nil || !strings.Contains(err.Error(), "panic in rule 1: boom") {
+		t.Errorf("got error %v", err)
+	}
+	if s := fmt.Sprintf("%+v", err); !strings.Contains(s, "callRecover") {
+		t.Errorf("got %q, want a stack trace", s)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic without RecoverPanics")
+		}
+	}()
+	_, _ = New().Transform("a", boom)
+}
+
+func TestMustache(t *testing.T) {
+	y := New(Lookup(LookupHandlers(map[string]string{"NAME": "World", "x": "1"})))
+	tests := []struct {
+		in, want string
+		ok       bool
+	}{
+		{"Hello {{NAME}}!", "Hello World!", true},
+		{"{{ x }}{{x}}", "11", true},
+		{"{{#section}}", "{{#section}}", true},
+		{"{{missing}}", "", false},
+	}
+	for _, tt := range tests {
+		got, err := applyRule(y, "mustache", tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("mustache(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := y.ParseStringRule("mustache:x"); err == nil {
+		t.Error("expected error for argument")
+	}
+}
+
+func TestDefaultProfile(t *testing.T) {
+	tests := []struct {
+		name        string
+		handlers    int
+		argHandlers int
+	}{
+		{"minimal", 3, 1},
+		{"standard", 6, 3},
+		{"full", len(New().Handlers), len(New().ArgHandlers)},
+		{"minmal", 3, 1},
+	}
+	for _, tt := range tests {
+		y := New(DefaultProfile(tt.name))
+		for i := 0; i < 2; i++ {
+			if len(y.Handlers) != tt.handlers || len(y.ArgHandlers) != tt.argHandlers {
+				t.Errorf("%s: got %d handlers and %d arg handlers, want %d and %d",
+					tt.name, len(y.Handlers), len(y.ArgHandlers), tt.handlers, tt.argHandlers)
+			}
+			if _, err := y.ParseStringRule("trim"); (err == nil) != (tt.name != "minmal") {
+				t.Errorf("%s: got error %v", tt.name, err)
+			}
+			y.Reset()
+		}
+	}
+}
+
+func TestDefaultProfileExpandEnv(t *testing.T) {
+	y := New(DefaultProfile("minimal"), ExpandEnv())
+	if _, err := y.Transform("${NOT_DEFINED_ANYWHERE}"); err == nil {
+		t.Error("expected error for unresolved variable")
+	}
+}
+
+func TestMemoizeLookups(t *testing.T) {
+	calls := 0
+	lookup := func(key string) (string, bool) {
+		calls++
+		return key, true
+	}
+	tests := []struct {
+		opts  []TransformOption
+		calls int
+	}{
+		{nil, 6},
+		{[]TransformOption{MemoizeLookups()}, 4},
+	}
+	for _, tt := range tests {
+		calls = 0
+		y := New(append(tt.opts, Lookup(lookup))...)
+		f, err := y.ParseStringRule(`expand:\$\{(?P<key>\w+)\}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 2; i++ {
+			if s, err := f("${a}${b}${a}"); err != nil || s != "aba" {
+				t.Errorf("got %q, %v, want %q", s, err, "aba")
+			}
+		}
+		if calls != tt.calls {
+			t.Errorf("got %d lookups, want %d", calls, tt.calls)
+		}
+	}
+}
+
+func TestBetweenRules(t *testing.T) {
+	y := New(BetweenRules(func(s string) (string, error) { return s + "|", nil }))
+	if err := y.AddStringRules("trim,upcase,nop"); err != nil {
+		t.Fatal(err)
+	}
+	if s, err := y.Transform(" a "); err != nil || s != "A||" {
+		t.Errorf("got %q, %v, want %q", s, err, "A||")
+	}
+	if s, err := y.Transform(" a ", y.Trim); err != nil || s != "a" {
+		t.Errorf("single rule: got %q, %v, want %q", s, err, "a")
+	}
+}
+
+func TestRuleSeparator(t *testing.T) {
+	y := New(RuleSeparator(';'))
+	if err := y.AddStringRules("trim; maxlines:1:,;upcase"); err != nil {
+		t.Fatal(err)
+	}
+	if s, err := y.Transform(" a\nb "); err != nil || s != "A\n," {
+		t.Errorf("got %q, %v, want %q", s, err, "A\n,")
+	}
+	if err := New().AddStringRules("trim,upcase"); err != nil {
+		t.Errorf("default separator: %v", err)
+	}
+}
+
+func TestExpandDefault(t *testing.T) {
+	y := New(Lookup(LookupHandlers(map[string]string{"a": "1"})))
+	tests := []struct {
+		rule, in, want string
+		ok             bool
+	}{
+		{`expand:\$\{(?P<key>\w+)\}:default=none`, "${a}/${b}", "1/none", true},
+		{`expand:\$\{(?P<key>\w+)\}:default=`, "${b}x", "x", true},
+		{`expand:\$\{(?P<key>\w+)\}:default=a:b`, "${b}", "a:b", true},
+		{`expand:\$\{(?P<key>\w+)\}`, "${b}", "", false},
+	}
+	for _, tt := range tests {
+		got, err := applyRule(y, tt.rule, tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestFallbackHandler(t *testing.T) {
+	y := New(FallbackHandler(func(tag string) (TransformFunc, error) {
+		switch {
+		case strings.HasPrefix(tag, "prefix-"):
+			p := strings.TrimPrefix(tag, "prefix-")
+			return func(s string) (string, error) { return p + s, nil }, nil
+		case tag == "broken":
+			return nil, errors.New("broken handler")
+		}
+		return nil, nil
+	}))
+	if s, err := applyRule(y, "prefix-x", "a"); err != nil || s != "xa" {
+		t.Errorf("got %q, %v, want %q", s, err, "xa")
+	}
+	if s, err := applyRule(y, "upcase", "a"); err != nil || s != "A" {
+		t.Errorf("got %q, %v, want %q", s, err, "A")
+	}
+	if _, err := y.ParseStringRule("broken"); err == nil || err.Error() != "broken handler" {
+		t.Errorf("got error %v, want %q", err, "broken handler")
+	}
+	if _, err := y.ParseStringRule("unknown"); err == nil {
+		t.Error("expected error for unknown tag")
+	}
+}
+
+func TestInternLookups(t *testing.T) {
+	lookup := func(key string) (string, bool) {
+		return string([]byte("value")), true
+	}
+	y := New(InternLookups(), Lookup(lookup))
+	f, err := y.ParseStringRule(`expand:\$\{(?P<key>\w+)\}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, _ := f("${A}")
+	b, _ := f("${B}")
+	if a != "value" || b != "value" || stringData(a) != stringData(b) {
+		t.Errorf("got %q and %q, want one shared string", a, b)
+	}
+	if s, _ := f("x${A}"); s != "xvalue" {
+		t.Errorf("got %q, want %q", s, "xvalue")
+	}
+
+	other, _ := New(Lookup(lookup)).ParseStringRule(`expand:\$\{(?P<key>\w+)\}`)
+	c, _ := other("${A}")
+	if stringData(a) == stringData(c) {
+		t.Error("interned string shared with a Transform not interning")
+	}
+}
+
+// stringData returns the address of the bytes of s.
+func stringData(s string) uintptr {
+	return (*reflect.StringHeader)(unsafe.Pointer(&s)).Data
+}
+
+func TestStopIfEmpty(t *testing.T) {
+	empty := func(string) (string, error) { return "", nil }
+	def := func(s string) (string, error) {
+		if s == "" {
+			return "default", nil
+		}
+		return s, nil
+	}
+	if s, err := New(StopIfEmpty()).Transform("a", empty, def); err != nil || s != "" {
+		t.Errorf("got %q, %v, want %q", s, err, "")
+	}
+	if s, err := New().Transform("a", empty, def); err != nil || s != "default" {
+		t.Errorf("without option: got %q, %v, want %q", s, err, "default")
+	}
+	if s, err := New(StopIfEmpty()).Transform("a", def, empty); err != nil || s != "" {
+		t.Errorf("got %q, %v, want %q", s, err, "")
+	}
+}
+
+func TestEnsureUTF8(t *testing.T) {
+	id := func(s string) (string, error) { return s, nil }
+	cut := func(s string) (string, error) { return s[:1], nil }
+	fails := func(string) (string, error) { return "", errors.New("failed") }
+
+	tests := []struct {
+		name string
+		y    *Transform
+		in   string
+		f    TransformFunc
+		want string
+		ok   bool
+	}{
+		{"valid", New(EnsureUTF8()), "é", id, "é", true},
+		{"invalid result", New(EnsureUTF8()), "é", cut, "", false},
+		{"without option", New(), "é", cut, "\xc3", true},
+		{"fail open", New(FailOpen(nil), EnsureUTF8()), "é", cut, "é", true},
+		{"fail open error", New(FailOpen(nil), EnsureUTF8()), "é", fails, "é", true},
+		{"fail open invalid input", New(FailOpen(nil), EnsureUTF8()), "\xff", id, "", false},
+		{"fail open invalid input error", New(FailOpen(nil), EnsureUTF8()), "\xff", fails, "", false},
+	}
+	for _, tt := range tests {
+		got, err := tt.y.Transform(tt.in, tt.f)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("%s: got %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestCapitalize(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"a", "A"},
+		{"é", "É"},
+		{"élan VITAL", "Élan vital"},
+		{"ölçek", "Ölçek"},
+		{"привет МИР", "Привет мир"},
+		{"я", "Я"},
+		{"東京タワー", "東京タワー"},
+		{"東", "東"},
+	}
+	y := New()
+	for _, tt := range tests {
+		if got, err := y.Capitalize(tt.in); err != nil || got != tt.want {
+			t.Errorf("Capitalize(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseStringRuleArgs(t *testing.T) {
+	y := New()
+	tests := []struct {
+		rule string
+		ok   bool
+	}{
+		{"upcase", true},
+		{"upcase:", true},
+		{"upcase:foo", true},
+		{"maxlines:5", true},
+		{"maxlines", false},
+		{"maxlines:5:x:y", false},
+		{"nosuchrule", false},
+	}
+	for _, tt := range tests {
+		if _, err := y.ParseStringRule(tt.rule); (err == nil) != tt.ok {
+			t.Errorf("ParseStringRule(%q): got error %v, want ok=%v", tt.rule, err, tt.ok)
+		}
+	}
+}
+
+func TestArgHandler(t *testing.T) {
+	repeat := func(arg string) (TransformFunc, error) {
+		return func(s string) (string, error) { return strings.Repeat(s, len(arg)), nil }, nil
+	}
+	y := New(
+		ArgHandler("Repeat", repeat),
+		ArgHandler("truncate", nil),
+		Handler("maxlines", func(s string) (string, error) { return "plain", nil }),
+	)
+	if s, err := applyRule(y, "repeat:xxx", "a"); err != nil || s != "aaa" {
+		t.Errorf("got %q, %v, want %q", s, err, "aaa")
+	}
+	if _, err := y.ParseStringRule("truncate:5"); err == nil {
+		t.Error("expected removed handler to be unknown")
+	}
+	if s, err := applyRule(y, "maxlines:1", "a"); err != nil || s != "plain" {
+		t.Errorf("got %q, %v, want %q", s, err, "plain")
+	}
+}
@@ -0,0 +1,147 @@
+package transform
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// fragmentSafe contains the non-alphanumeric characters that are allowed
+// unescaped in a URL fragment as per RFC 3986.
+const fragmentSafe = "-._~!$&'()*+,;=:@/?"
+
+// FragEscape percent-encodes a string for use in a URL fragment. ASCII
+// letters and digits as well as the characters -._~!$&'()*+,;=:@/? are left
+// unescaped, all other bytes (including "%" and spaces) are escaped. Unlike
+// url.QueryEscape, spaces become "%20", and unlike url.PathEscape, "/" and
+// "?" are kept.
+func (*Transform) FragEscape(s string) (string, error) {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte(fragmentSafe, c) != -1 {
+			b.WriteByte(c)
+		} else {
+			b.WriteByte('%')
+			b.WriteByte(hex[c>>4])
+			b.WriteByte(hex[c&15])
+		}
+	}
+	return b.String(), nil
+}
+
+// URLBase returns the URL without user info, query string and fragment,
+// i.e. only scheme, host and path are kept. The URL must be absolute.
+func (t *Transform) URLBase(s string) (string, error) {
+	return t.URLPath(false)(s)
+}
+
+// URLPath returns a function that parses a URL and returns only its scheme,
+// host and path, or only its path if pathOnly is true. User info, query
+// string and fragment are dropped. Unless pathOnly is true, the URL must be
+// absolute, i.e. have a scheme and a host.
+func (*Transform) URLPath(pathOnly bool) TransformFunc {
+	return func(s string) (string, error) {
+		u, err := url.Parse(s)
+		if err != nil {
+			return "", errors.Wrap(err, "url")
+		}
+		if pathOnly {
+			return u.EscapedPath(), nil
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return "", errors.New("url: not an absolute URL: " + s)
+		}
+		u.User = nil
+		u.RawQuery = ""
+		u.ForceQuery = false
+		u.Fragment = ""
+		u.RawFragment = ""
+		return u.String(), nil
+	}
+}
+
+// PathEscape escapes the string for use as a URL path segment, e.g. spaces
+// become "%20" rather than "+" as with query escaping.
+func (*Transform) PathEscape(s string) (string, error) {
+	return url.PathEscape(s), nil
+}
+
+// PathUnescape reverses PathEscape.
+func (*Transform) PathUnescape(s string) (string, error) {
+	u, err := url.PathUnescape(s)
+	if err != nil {
+		return "", errors.Wrap(err, "pathunescape")
+	}
+	return u, nil
+}
+
+// defaultPorts indexes the default ports of common URL schemes.
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+	"ws":    "80",
+	"wss":   "443",
+	"ftp":   "21",
+}
+
+// URLNormalize parses an absolute URL and returns it in canonical form: the
+// scheme and host are lowercased, the scheme's default port is removed, and
+// the path is cleaned of empty, "." and ".." segments (keeping a trailing
+// slash), with an empty path becoming "/". The path is cleaned in its escaped
+// form, so encoded slashes ("%2F") remain part of their segment. Query
+// string and fragment are kept as they are.
+func (*Transform) URLNormalize(s string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(s))
+	if err != nil {
+		return "", errors.Wrap(err, "urlnormalize")
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return "", errors.New("urlnormalize: not an absolute URL: " + s)
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	host, port := u.Hostname(), u.Port()
+	host = strings.ToLower(host)
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if port != "" && port != defaultPorts[u.Scheme] {
+		host += ":" + port
+	}
+	u.Host = host
+
+	p := "/"
+	if ep := u.EscapedPath(); ep != "" {
+		p = path.Clean("/" + ep)
+		if strings.HasSuffix(ep, "/") && p != "/" {
+			p += "/"
+		}
+	}
+	if u.Path, err = url.PathUnescape(p); err != nil {
+		return "", errors.Wrap(err, "urlnormalize")
+	}
+	u.RawPath = p
+	return u.String(), nil
+}
+
+// CleanURLPath returns a function that lexically cleans a URL path using
+// path.Clean, e.g. "/a//b/./c/" becomes "/a/b/c". If leadingSlash is true,
+// the result always starts with a slash. An empty path remains empty, or
+// becomes "/" if leadingSlash is true. The path is not resolved against
+// anything: ".." segments at the root of a rooted path are dropped, and
+// leading ".." segments of a relative path are kept.
+func (*Transform) CleanURLPath(leadingSlash bool) TransformFunc {
+	return func(s string) (string, error) {
+		if s == "" && !leadingSlash {
+			return "", nil
+		}
+		if leadingSlash && !strings.HasPrefix(s, "/") {
+			s = "/" + s
+		}
+		return path.Clean(s), nil
+	}
+}
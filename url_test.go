@@ -0,0 +1,126 @@
+package transform
+
+import (
+	"testing"
+)
+
+func TestFragEscape(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"section 1", "section%201"},
+		{"a/b?c=d&e", "a/b?c=d&e"},
+		{"100%#x", "100%25%23x"},
+		{"ü", "%C3%BC"},
+	}
+	y := New()
+	for _, tt := range tests {
+		if got, err := applyRule(y, "fragescape", tt.in); err != nil || got != tt.want {
+			t.Errorf("fragescape(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestURLBase(t *testing.T) {
+	tests := []struct {
+		in, want string
+		ok       bool
+	}{
+		{"https://user:pw@example.com/a/b?q=1#frag", "https://example.com/a/b", true},
+		{"http://example.com?", "http://example.com", true},
+		{"/a/b?q=1", "", false},
+		{"example.com/a", "", false},
+		{"mailto:me@example.com", "", false},
+	}
+	y := New()
+	for _, tt := range tests {
+		got, err := applyRule(y, "urlbase", tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("urlbase(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestPathEscape(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"a b", "a%20b"},
+		{"a/b?c", "a%2Fb%3Fc"},
+		{"ü+&", "%C3%BC+&"},
+	}
+	y := New()
+	for _, tt := range tests {
+		got, err := applyRule(y, "pathescape", tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("pathescape(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+		if back, err := applyRule(y, "pathunescape", got); err != nil || back != tt.in {
+			t.Errorf("pathunescape(%q) = %q, %v, want %q", got, back, err, tt.in)
+		}
+	}
+	if _, err := applyRule(y, "pathunescape", "%zz"); err == nil {
+		t.Error("expected error for invalid escape")
+	}
+}
+
+func TestURLPath(t *testing.T) {
+	tests := []struct {
+		rule, in, want string
+		ok             bool
+	}{
+		{"urlpath", "https://user@example.com/a%20b?q=1#f", "https://example.com/a%20b", true},
+		{"urlpath:pathonly", "https://example.com/a%20b?q=1#f", "/a%20b", true},
+		{"urlpath:pathonly", "/a/b?q=1", "/a/b", true},
+		{"urlpath", "/a/b?q=1", "", false},
+		{"urlpath", "//example.com/a", "", false},
+		{"urlpath", "mailto:me@example.com", "", false},
+	}
+	y := New()
+	for _, tt := range tests {
+		got, err := applyRule(y, tt.rule, tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := y.ParseStringRule("urlpath:x"); err == nil {
+		t.Error("expected error for invalid mode")
+	}
+}
+
+func TestURLNormalize(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"HTTP://Example.COM:80", "http://example.com/"},
+		{"https://example.com:8443/a//b/./c/../d/?q=1#f", "https://example.com:8443/a/b/d/?q=1#f"},
+		{"HTTP://Example.COM:80/a/%2F/b", "http://example.com/a/%2F/b"},
+		{"http://example.com/a%20b/../c%2Fd", "http://example.com/c%2Fd"},
+		{"http://[::1]:80/x", "http://[::1]/x"},
+	}
+	y := New()
+	for _, tt := range tests {
+		if got, err := y.URLNormalize(tt.in); err != nil || got != tt.want {
+			t.Errorf("URLNormalize(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestCleanURLPath(t *testing.T) {
+	tests := []struct {
+		rule, in, want string
+	}{
+		{"cleanurlpath", "/a//b/./c/", "/a/b/c"},
+		{"cleanurlpath", "a/../../b", "../b"},
+		{"cleanurlpath", "/../a", "/a"},
+		{"cleanurlpath", "", ""},
+		{"cleanurlpath:slash", "a/b/", "/a/b"},
+		{"cleanurlpath:slash", "", "/"},
+	}
+	y := New()
+	for _, tt := range tests {
+		if got, err := y.Apply(tt.rule, tt.in); err != nil || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+}
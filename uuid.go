@@ -0,0 +1,36 @@
+package transform
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// UUIDNorm validates a UUID and returns it in the canonical lowercase
+// hyphenated form. Uppercase, unhyphenated, brace-wrapped ("{...}") and URN
+// ("urn:uuid:...") forms are accepted.
+func (*Transform) UUIDNorm(s string) (string, error) {
+	u := strings.TrimSpace(s)
+	if len(u) > 9 && strings.EqualFold(u[:9], "urn:uuid:") {
+		u = u[9:]
+	} else if strings.HasPrefix(u, "{") && strings.HasSuffix(u, "}") {
+		u = u[1 : len(u)-1]
+	}
+
+	if len(u) == 36 {
+		if u[8] != '-' || u[13] != '-' || u[18] != '-' || u[23] != '-' {
+			return "", errors.New("invalid UUID: " + s)
+		}
+		u = strings.ReplaceAll(u, "-", "")
+	}
+	if len(u) != 32 {
+		return "", errors.New("invalid UUID: " + s)
+	}
+	if _, err := hex.DecodeString(u); err != nil {
+		return "", errors.New("invalid UUID: " + s)
+	}
+
+	u = strings.ToLower(u)
+	return u[:8] + "-" + u[8:12] + "-" + u[12:16] + "-" + u[16:20] + "-" + u[20:], nil
+}
@@ -0,0 +1,28 @@
+package transform
+
+import (
+	"testing"
+)
+
+func TestUUIDNorm(t *testing.T) {
+	const want = "123e4567-e89b-12d3-a456-426614174000"
+	tests := []struct {
+		in, want string
+		ok       bool
+	}{
+		{"123E4567-E89B-12D3-A456-426614174000", want, true},
+		{"123e4567e89b12d3a456426614174000", want, true},
+		{"{123e4567-e89b-12d3-a456-426614174000}", want, true},
+		{"URN:UUID:123e4567-e89b-12d3-a456-426614174000", want, true},
+		{"123e4567-e89b12d3-a456-4266141740000", "", false},
+		{"123e4567-e89b-12d3-a456-42661417400g", "", false},
+		{"123e4567", "", false},
+	}
+	y := New()
+	for _, tt := range tests {
+		got, err := applyRule(y, "uuidnorm", tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("uuidnorm(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package transform
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// semverRE matches a semantic version as specified by https://semver.org,
+// with the pre-release and build metadata in separate subexpressions.
+var semverRE = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+	`(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+	`(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
+// Semver returns a function that validates a semantic version, optionally
+// prefixed with "v", and returns it with the "v" prefix removed, or added if
+// prefix is true. Pre-release and build metadata segments are validated as
+// per the specification and kept as they are.
+func (*Transform) Semver(prefix bool) TransformFunc {
+	return func(s string) (string, error) {
+		v := strings.TrimPrefix(strings.TrimSpace(s), "v")
+		if !semverRE.MatchString(v) {
+			return "", errors.New("invalid semantic version: " + s)
+		}
+		if prefix {
+			v = "v" + v
+		}
+		return v, nil
+	}
+}
@@ -0,0 +1,31 @@
+package transform
+
+import (
+	"testing"
+)
+
+func TestSemver(t *testing.T) {
+	tests := []struct {
+		rule, in, want string
+		ok             bool
+	}{
+		{"semver", "v1.2.3", "1.2.3", true},
+		{"semver:strip", " 1.2.3-rc.1+build.5 ", "1.2.3-rc.1+build.5", true},
+		{"semver:v", "1.0.0-alpha", "v1.0.0-alpha", true},
+		{"semver:v", "v0.0.1", "v0.0.1", true},
+		{"semver", "1.2", "", false},
+		{"semver", "01.2.3", "", false},
+		{"semver", "1.2.3-01", "", false},
+		{"semver", "1.2.3+", "", false},
+	}
+	y := New()
+	for _, tt := range tests {
+		got, err := applyRule(y, tt.rule, tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("%s(%q) = %q, %v, want %q", tt.rule, tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := y.ParseStringRule("semver:x"); err == nil {
+		t.Error("expected error for invalid mode")
+	}
+}
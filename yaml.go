@@ -0,0 +1,82 @@
+package transform
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// yamlIndicators contains the characters that have a special meaning at the
+// start of a plain YAML scalar.
+const yamlIndicators = "-?:,[]{}#&*!|>'\"%@`"
+
+// yamlSpecialRE matches plain scalars that YAML would not resolve to a
+// string, e.g. booleans, nulls, numbers including YAML 1.1 sexagesimal ones
+// like 12:30, and dates and timestamps.
+var yamlSpecialRE = regexp.MustCompile(`(?i)^(y|yes|n|no|true|false|on|off|null|~|` +
+	`[-+]?(\.[0-9]+|[0-9][0-9_]*(\.[0-9_]*)?)([eE][-+]?[0-9]+)?|` +
+	`0x[0-9a-f_]+|0o?[0-7_]+|0b[01_]+|[-+]?\.inf|\.nan|` +
+	`[-+]?[0-9][0-9_]*(:[0-5]?[0-9])+(\.[0-9_]*)?|` +
+	`[0-9]{4}-[0-9]{1,2}-[0-9]{1,2}(([t]|[ \t]+)[0-9]{1,2}:[0-9]{2}:[0-9]{2}(\.[0-9]*)?` +
+	`([ \t]*(z|[-+][0-9]{1,2}(:[0-9]{2})?))?)?)$`)
+
+// YAMLEscape returns the string as a YAML scalar: unchanged if it can be used
+// as a plain scalar, or enclosed in double quotes with special characters
+// escaped otherwise. Strings that would be interpreted as booleans, nulls or
+// numbers are quoted as well.
+func (*Transform) YAMLEscape(s string) (string, error) {
+	if yamlPlainSafe(s) {
+		return s, nil
+	}
+
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if unicode.IsControl(r) || r == '\ufeff' {
+				if r <= 0xff {
+					fmt.Fprintf(&b, `\x%02x`, r)
+				} else {
+					fmt.Fprintf(&b, `\u%04x`, r)
+				}
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String(), nil
+}
+
+// yamlPlainSafe reports whether the string can be emitted as a plain YAML
+// scalar without changing its meaning.
+func yamlPlainSafe(s string) bool {
+	switch {
+	case s == "",
+		strings.TrimSpace(s) != s,
+		strings.ContainsRune(yamlIndicators, rune(s[0])),
+		strings.HasSuffix(s, ":"),
+		strings.Contains(s, ": "),
+		strings.Contains(s, " #"),
+		yamlSpecialRE.MatchString(s):
+		return false
+	}
+	for _, r := range s {
+		if unicode.IsControl(r) || r == '\ufeff' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,53 @@
+package transform
+
+import (
+	"testing"
+)
+
+func TestYAMLEscape(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"hello", "hello"},
+		{"hello world", "hello world"},
+		{"a:b", "a:b"},
+		{"", `""`},
+		{" x", `" x"`},
+		{"- item", `"- item"`},
+		{"? key", `"? key"`},
+		{":x", `":x"`},
+		{"#comment", `"#comment"`},
+		{"&anchor", `"&anchor"`},
+		{"*alias", `"*alias"`},
+		{"!tag", `"!tag"`},
+		{"[list]", `"[list]"`},
+		{"{map}", `"{map}"`},
+		{"|", `"|"`},
+		{"@x", `"@x"`},
+		{"key: value", `"key: value"`},
+		{"key:", `"key:"`},
+		{"a #b", `"a #b"`},
+		{"yes", `"yes"`},
+		{"Off", `"Off"`},
+		{"null", `"null"`},
+		{"~", `"~"`},
+		{"42", `"42"`},
+		{"-1.5e3", `"-1.5e3"`},
+		{"0x1F", `"0x1F"`},
+		{".inf", `".inf"`},
+		{"12:30", `"12:30"`},
+		{"1:20:30.5", `"1:20:30.5"`},
+		{"2024-01-02", `"2024-01-02"`},
+		{"2024-01-02T10:20:30Z", `"2024-01-02T10:20:30Z"`},
+		{"2024-01-02 10:20:30 +02:00", `"2024-01-02 10:20:30 +02:00"`},
+		{"12:60", "12:60"},
+		{"say \"hi\"\n", `"say \"hi\"\n"`},
+		{"tab\there", `"tab\there"`},
+	}
+	y := New()
+	for _, tt := range tests {
+		if got, err := y.YAMLEscape(tt.in); err != nil || got != tt.want {
+			t.Errorf("YAMLEscape(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}